@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	configv1alpha1 "github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedconfigv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
 	caddyv1alpha1 "github.com/crossplane/provider-caddy/apis/v1alpha1"
 )
 
@@ -29,6 +30,7 @@ func init() {
 	AddToSchemes = append(AddToSchemes,
 		caddyv1alpha1.SchemeBuilder.AddToScheme,
 		configv1alpha1.SchemeBuilder.AddToScheme,
+		namespacedconfigv1alpha1.SchemeBuilder.AddToScheme,
 	)
 }
 
@@ -0,0 +1,120 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+)
+
+// TypeUpstreamsHealthy indicates whether the upstreams a route declares are
+// healthy, according to Caddy.
+const TypeUpstreamsHealthy xpv1.ConditionType = "UpstreamsHealthy"
+
+// Reasons an UpstreamsHealthy condition may be in its status.
+const (
+	ReasonAllHealthy   xpv1.ConditionReason = "AllHealthy"
+	ReasonDegraded     xpv1.ConditionReason = "Degraded"
+	ReasonAllUnhealthy xpv1.ConditionReason = "AllUnhealthy"
+	ReasonNoUpstreams  xpv1.ConditionReason = "NoUpstreams"
+	ReasonDynamic      xpv1.ConditionReason = "Dynamic"
+	ReasonNoHealthData xpv1.ConditionReason = "NoHealthData"
+)
+
+// UpstreamsHealthy returns a condition that indicates how many of a route's
+// total upstreams are healthy. It is True only if all of them are.
+func UpstreamsHealthy(healthy, total int) xpv1.Condition {
+	c := xpv1.Condition{
+		Type:               TypeUpstreamsHealthy,
+		LastTransitionTime: metav1.Now(),
+		Message:            fmt.Sprintf("%d of %d upstreams healthy", healthy, total),
+	}
+	switch {
+	case total == 0:
+		c.Status, c.Reason, c.Message = corev1.ConditionUnknown, ReasonNoUpstreams, "The route has no upstreams"
+	case healthy >= total:
+		c.Status, c.Reason = corev1.ConditionTrue, ReasonAllHealthy
+	case healthy == 0:
+		c.Status, c.Reason = corev1.ConditionFalse, ReasonAllUnhealthy
+	default:
+		c.Status, c.Reason = corev1.ConditionFalse, ReasonDegraded
+	}
+	return c
+}
+
+// DynamicUpstreamsHealth returns a condition that indicates the health of a
+// route's upstreams is unknown because Caddy looks them up dynamically.
+func DynamicUpstreamsHealth() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeUpstreamsHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDynamic,
+		Message:            "Caddy does not report the health of dynamic upstreams",
+	}
+}
+
+// UnknownUpstreamsHealth returns a condition that indicates the health of a
+// route's upstreams is unknown because Caddy doesn't count their failed
+// requests, which it only does for passive health checks with a fail
+// duration.
+func UnknownUpstreamsHealth() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeUpstreamsHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoHealthData,
+		Message:            "Caddy only reports upstream health for routes with passive health checks that set a failDuration",
+	}
+}
+
+// TypeConflict indicates whether a route is shadowed by an earlier route on
+// the same server whose match conditions overlap with its own.
+const TypeConflict xpv1.ConditionType = "Conflict"
+
+// Reasons a Conflict condition may be in its status.
+const (
+	ReasonShadowed   xpv1.ConditionReason = "Shadowed"
+	ReasonNoConflict xpv1.ConditionReason = "NoConflict"
+)
+
+// Shadowed returns a condition that indicates the route is shadowed by the
+// supplied winning route, which Caddy evaluates first.
+func Shadowed(winner string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeConflict,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonShadowed,
+		Message:            fmt.Sprintf("Requests matching this route may be handled by %s, which overlaps with it and is evaluated first", winner),
+	}
+}
+
+// NoConflict returns a condition that indicates no earlier route overlaps
+// with the route.
+func NoConflict() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeConflict,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoConflict,
+	}
+}
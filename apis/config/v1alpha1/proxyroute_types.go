@@ -18,29 +18,75 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
 )
 
 // ProxyRouteParameters define the desired state of a Caddy reverse proxy route.
+// +kubebuilder:validation:XValidation:rule="!(has(self.match) && has(self.matchSets))",message="match and matchSets are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="(has(self.upstreams) || has(self.upstreamsFrom)) != has(self.dynamicUpstreams)",message="exactly one of static upstreams (upstreams or upstreamsFrom) and dynamicUpstreams is required"
+// +kubebuilder:validation:XValidation:rule="!(has(self.dynamicUpstreams) && has(self.minHealthyUpstreams))",message="minHealthyUpstreams is not supported with dynamicUpstreams"
+// +kubebuilder:validation:XValidation:rule="!(has(self.dynamicUpstreams) && has(self.canary))",message="canary is not supported with dynamicUpstreams"
+// +kubebuilder:validation:XValidation:rule="!has(self.canary) || !has(self.loadBalancing) || !has(self.loadBalancing.policy) || self.loadBalancing.policy == 'weighted_round_robin'",message="canary requires the weighted_round_robin load balancing policy"
+// +kubebuilder:validation:XValidation:rule="!has(self.transport) || !has(self.transport.versions) || !('h2c' in self.transport.versions) || !has(self.tls) || !has(self.tls.enabled) || !self.tls.enabled",message="transport version h2c is not supported with TLS"
+// +kubebuilder:validation:XValidation:rule="!has(self.transport) || !has(self.transport.versions) || !('3' in self.transport.versions) || (has(self.tls) && has(self.tls.enabled) && self.tls.enabled)",message="transport version 3 requires TLS"
 type ProxyRouteParameters struct {
-	// CaddyEndpoint is the Caddy admin API endpoint (e.g., "http://localhost:2019")
-	// +kubebuilder:validation:Required
-	CaddyEndpoint string `json:"caddyEndpoint"`
+	// CaddyEndpoint overrides the Caddy admin API endpoint configured by the
+	// referenced ProviderConfig (e.g., "http://localhost:2019" or
+	// "unix//run/caddy/admin.sock").
+	// +optional
+	CaddyEndpoint *string `json:"caddyEndpoint,omitempty"`
 
 	// ServerName is the name of the Caddy server to add this route to.
 	// If not specified, defaults to "srv0".
 	// +optional
 	ServerName *string `json:"serverName,omitempty"`
 
-	// Match defines the conditions to match for this route.
+	// Priority determines the order in which Caddy evaluates this route
+	// relative to the server's other routes. Routes with a higher priority
+	// are evaluated first. Routes not managed by a ProxyRoute have priority
+	// 0. The priority of a namespaced ProxyRoute is capped at 0, so that it
+	// never outranks routes of cluster scoped ProxyRoutes or unmanaged routes.
+	// Defaults to 0.
+	// +optional
+	Priority *int `json:"priority,omitempty"`
+
+	// Match defines the conditions to match for this route. Use MatchSets
+	// to match any of several sets of conditions instead.
 	// +optional
 	Match *RouteMatch `json:"match,omitempty"`
 
+	// MatchSets defines alternative sets of conditions to match for this
+	// route. The route matches a request if any set matches it, and a set
+	// matches if all of its conditions do. Mutually exclusive with Match.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	MatchSets []RouteMatch `json:"matchSets,omitempty"`
+
 	// Upstreams defines the backend servers to proxy to.
-	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
-	Upstreams []Upstream `json:"upstreams"`
+	// +optional
+	Upstreams []Upstream `json:"upstreams,omitempty"`
+
+	// UpstreamsFrom discovers additional backend servers to proxy to from
+	// Kubernetes. The provider keeps Caddy's upstreams in sync with them.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	UpstreamsFrom []UpstreamSource `json:"upstreamsFrom,omitempty"`
+
+	// DynamicUpstreams configures Caddy to look up the backend servers to
+	// proxy to itself, e.g. in DNS, rather than proxying to a static list of
+	// upstreams. Mutually exclusive with Upstreams and UpstreamsFrom.
+	// +optional
+	DynamicUpstreams *DynamicUpstreams `json:"dynamicUpstreams,omitempty"`
+
+	// Canary splits traffic between the upstreams above, the stable group,
+	// and a canary group of upstreams, using the weighted_round_robin load
+	// balancing policy.
+	// +optional
+	Canary *Canary `json:"canary,omitempty"`
 
 	// LoadBalancing defines the load balancing policy.
 	// +optional
@@ -57,10 +103,36 @@ type ProxyRouteParameters struct {
 	// TLS defines TLS settings for upstream connections.
 	// +optional
 	TLS *UpstreamTLS `json:"tls,omitempty"`
+
+	// Transport tunes how Caddy connects to upstreams over HTTP.
+	// +optional
+	Transport *HTTPTransport `json:"transport,omitempty"`
+
+	// MinHealthyUpstreams is the number of upstreams Caddy must report as
+	// healthy before the ProxyRoute becomes Ready. By default a ProxyRoute is
+	// Ready as soon as its route exists, regardless of upstream health. Caddy
+	// only reports upstreams as unhealthy if passive health checks with a
+	// failDuration are configured; otherwise every upstream it reports counts
+	// as healthy.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinHealthyUpstreams *int `json:"minHealthyUpstreams,omitempty"`
 }
 
-// RouteMatch defines the matching conditions for a route.
+// RouteMatch defines the matching conditions for a route. A request matches
+// if it meets all of the conditions.
 type RouteMatch struct {
+	MatchConditions `json:",inline"`
+
+	// Not matches requests that match none of the supplied sets of
+	// conditions.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Not []MatchConditions `json:"not,omitempty"`
+}
+
+// MatchConditions are conditions a request must meet.
+type MatchConditions struct {
 	// Host matches the request host (domain names).
 	// +optional
 	Host []string `json:"host,omitempty"`
@@ -77,6 +149,63 @@ type RouteMatch struct {
 	// Headers matches request headers.
 	// +optional
 	Headers map[string][]string `json:"headers,omitempty"`
+
+	// Query matches query string parameters. A parameter matches if it has
+	// any of the supplied values; "*" matches any value.
+	// +optional
+	Query map[string][]string `json:"query,omitempty"`
+
+	// RemoteIP matches the IP address of the immediate peer against IP
+	// addresses and CIDR ranges, or the shortcut "private_ranges".
+	// +kubebuilder:validation:MaxItems=256
+	// +optional
+	RemoteIP []IPRange `json:"remoteIp,omitempty"`
+
+	// ClientIP matches the IP address of the client, which is taken from
+	// headers such as X-Forwarded-For when the request came through a
+	// trusted proxy, against IP addresses and CIDR ranges, or the shortcut
+	// "private_ranges".
+	// +kubebuilder:validation:MaxItems=256
+	// +optional
+	ClientIP []IPRange `json:"clientIp,omitempty"`
+
+	// Protocol matches the request protocol.
+	// +kubebuilder:validation:Enum=http;https;grpc;http/1.0;http/1.1;http/2;http/2+;http/3
+	// +optional
+	Protocol *string `json:"protocol,omitempty"`
+
+	// PathRegexp matches the request path against a regular expression.
+	// +optional
+	PathRegexp *RegexpMatch `json:"pathRegexp,omitempty"`
+
+	// HeaderRegexp matches request headers, keyed by header name, against
+	// regular expressions.
+	// +optional
+	HeaderRegexp map[string]RegexpMatch `json:"headerRegexp,omitempty"`
+
+	// Expression matches requests for which the supplied CEL expression,
+	// which may use Caddy placeholders, evaluates to true.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Expression *string `json:"expression,omitempty"`
+}
+
+// An IPRange is an IP address, a CIDR range, or "private_ranges", which
+// stands for all private IPv4 and IPv6 ranges.
+// +kubebuilder:validation:MaxLength=64
+// +kubebuilder:validation:XValidation:rule="self == 'private_ranges' || isIP(self) || isCIDR(self)",message="must be an IP address, a CIDR range, or private_ranges"
+type IPRange string
+
+// RegexpMatch matches a value against a regular expression.
+type RegexpMatch struct {
+	// Name of the match. Capture groups are made available to handlers as
+	// placeholders under this name, e.g. {re.name.1}.
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// Pattern is the regular expression, in RE2 syntax.
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
 }
 
 // Upstream represents a backend server.
@@ -89,16 +218,181 @@ type Upstream struct {
 	// MaxRequests is the maximum number of concurrent requests to this upstream.
 	// +optional
 	MaxRequests *int `json:"maxRequests,omitempty"`
+
+	// Weight is this upstream's share of traffic relative to the other
+	// upstreams in its group, when using the weighted_round_robin load
+	// balancing policy. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+}
+
+// An UpstreamSource discovers upstreams from Kubernetes.
+type UpstreamSource struct {
+	// Service discovers an upstream for each ready endpoint of a Kubernetes
+	// Service, using its EndpointSlices.
+	Service ServiceUpstreams `json:"service"`
+}
+
+// ServiceUpstreams selects the endpoints of a Kubernetes Service.
+type ServiceUpstreams struct {
+	// Name of the Service.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Service. A namespaced ProxyRoute may only use
+	// Services in its own namespace, which is the default. Required for a
+	// cluster scoped ProxyRoute.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Port is the name of the Service port to proxy to. Required if the
+	// Service has more than one port.
+	// +optional
+	Port *string `json:"port,omitempty"`
+}
+
+// A Canary is a group of upstreams that receives a percentage of a route's
+// traffic, either fixed or progressively increased over time.
+// +kubebuilder:validation:XValidation:rule="has(self.upstreams) || has(self.upstreamsFrom)",message="at least one of upstreams and upstreamsFrom is required"
+// +kubebuilder:validation:XValidation:rule="!(has(self.weight) && has(self.progressive))",message="weight and progressive are mutually exclusive"
+type Canary struct {
+	// Upstreams are the backend servers of the canary group.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	Upstreams []Upstream `json:"upstreams,omitempty"`
+
+	// UpstreamsFrom discovers backend servers of the canary group from
+	// Kubernetes.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	UpstreamsFrom []UpstreamSource `json:"upstreamsFrom,omitempty"`
+
+	// Weight is the percentage of traffic sent to the canary group. The
+	// stable group receives the remainder. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+
+	// Progressive shifts traffic from the stable group to the canary group
+	// over time, rather than sending it a fixed percentage.
+	// +optional
+	Progressive *ProgressiveCanary `json:"progressive,omitempty"`
+}
+
+// A ProgressiveCanary shifts traffic to a canary group in steps. If any of
+// the canary group's upstreams becomes unhealthy while traffic is shifting,
+// the canary is rolled back and receives no traffic until its upstreams
+// change. Caddy only reports unhealthy upstreams if passive health checks
+// with a failDuration are configured.
+type ProgressiveCanary struct {
+	// StepWeight is the percentage of traffic shifted to the canary group at
+	// each step. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	StepWeight *int `json:"stepWeight,omitempty"`
+
+	// StepInterval is how long to wait between steps. Defaults to 5m.
+	// +optional
+	StepInterval *metav1.Duration `json:"stepInterval,omitempty"`
+
+	// MaxWeight is the percentage of traffic at which the canary is
+	// promoted and shifting stops. Defaults to 100.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxWeight *int `json:"maxWeight,omitempty"`
+}
+
+// Sources of dynamic upstreams.
+const (
+	DynamicUpstreamsSourceSRV = "SRV"
+	DynamicUpstreamsSourceA   = "A"
+)
+
+// DynamicUpstreams defines how Caddy looks up upstreams in DNS.
+// +kubebuilder:validation:XValidation:rule="self.source == 'A' || !has(self.port)",message="port is only supported by the A source"
+type DynamicUpstreams struct {
+	// Source of the upstreams. SRV looks up SRV records, each of which
+	// specifies the host and port of an upstream. A looks up A and AAAA
+	// records, and dials each address on Port.
+	// +kubebuilder:validation:Enum=SRV;A
+	Source string `json:"source"`
+
+	// Name is the domain name to look up, e.g. "_http._tcp.example.com" for
+	// an SRV source.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Port is the port to dial the addresses of an A source on. Defaults to
+	// 80.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Refresh is how often Caddy looks the upstreams up again. Defaults to
+	// 1m.
+	// +optional
+	Refresh *string `json:"refresh,omitempty"`
+
+	// Resolvers are the addresses of the DNS servers to use for lookups,
+	// e.g. "8.8.8.8:53". Defaults to the system resolver.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Resolvers []string `json:"resolvers,omitempty"`
+
+	// DialTimeout is how long to wait for a connection to an upstream to be
+	// established.
+	// +optional
+	DialTimeout *string `json:"dialTimeout,omitempty"`
+
+	// DialFallbackDelay is how long to wait before trying another of an
+	// upstream's addresses when a connection to the first is slow to be
+	// established. A negative value disables this fallback.
+	// +optional
+	DialFallbackDelay *string `json:"dialFallbackDelay,omitempty"`
 }
 
 // LoadBalancing defines load balancing configuration.
+// +kubebuilder:validation:XValidation:rule="(has(self.policy) && self.policy == 'header') == has(self.header)",message="header must be set if and only if policy is header"
+// +kubebuilder:validation:XValidation:rule="(has(self.policy) && self.policy == 'cookie') == has(self.cookie)",message="cookie must be set if and only if policy is cookie"
+// +kubebuilder:validation:XValidation:rule="(has(self.policy) && self.policy == 'query') == has(self.query)",message="query must be set if and only if policy is query"
+// +kubebuilder:validation:XValidation:rule="!has(self.fallback) || (has(self.policy) && self.policy in ['header', 'cookie', 'query'])",message="fallback is only supported by the header, cookie, and query policies"
 type LoadBalancing struct {
 	// Policy is the load balancing policy to use.
-	// Options: "random", "round_robin", "least_conn", "ip_hash", "header", "cookie"
-	// +kubebuilder:validation:Enum=random;round_robin;least_conn;ip_hash;header;cookie
+	// Options: "random", "round_robin", "weighted_round_robin", "least_conn",
+	// "first", "ip_hash", "client_ip_hash", "uri_hash", "header", "cookie",
+	// "query"
+	// +kubebuilder:validation:Enum=random;round_robin;weighted_round_robin;least_conn;first;ip_hash;client_ip_hash;uri_hash;header;cookie;query
 	// +optional
 	Policy *string `json:"policy,omitempty"`
 
+	// Header configures the header policy, which selects an upstream by
+	// hashing the value of a request header.
+	// +optional
+	Header *HeaderPolicy `json:"header,omitempty"`
+
+	// Cookie configures the cookie policy, which pins clients to an
+	// upstream using a cookie.
+	// +optional
+	Cookie *CookiePolicy `json:"cookie,omitempty"`
+
+	// Query configures the query policy, which selects an upstream by
+	// hashing the value of a request query parameter.
+	// +optional
+	Query *QueryPolicy `json:"query,omitempty"`
+
+	// Fallback is the policy used by the header, cookie, and query policies
+	// when a request doesn't have the header, cookie, or query parameter
+	// they select an upstream by. Defaults to random.
+	// +kubebuilder:validation:Enum=random;round_robin;least_conn;first;ip_hash;client_ip_hash;uri_hash
+	// +optional
+	Fallback *string `json:"fallback,omitempty"`
+
 	// TryDuration is how long to try selecting available backends.
 	// +optional
 	TryDuration *string `json:"tryDuration,omitempty"`
@@ -106,6 +400,70 @@ type LoadBalancing struct {
 	// TryInterval is how long to wait between retries.
 	// +optional
 	TryInterval *string `json:"tryInterval,omitempty"`
+
+	// Retries is how many times to retry selecting an available backend
+	// after the first attempt fails. Retries stop when TryDuration, if set,
+	// elapses.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+
+	// RetryMatch restricts retries to requests that match any of the
+	// supplied sets of conditions. By default only GET requests are retried
+	// after a connection to a backend was established.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	RetryMatch []RouteMatch `json:"retryMatch,omitempty"`
+}
+
+// HeaderPolicy configures the header load balancing policy.
+type HeaderPolicy struct {
+	// Field is the name of the request header to hash.
+	// +kubebuilder:validation:MinLength=1
+	Field string `json:"field"`
+}
+
+// CookiePolicy configures the cookie load balancing policy.
+type CookiePolicy struct {
+	// Name of the cookie. Defaults to "lb".
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// SecretRef references the key of a Secret whose value is used to sign
+	// the cookie, so clients can't choose an upstream by forging it.
+	// +optional
+	SecretRef *SecretKeyReference `json:"secretRef,omitempty"`
+
+	// MaxAge is how long the cookie lasts. By default it lasts until the
+	// client's session ends.
+	// +optional
+	MaxAge *string `json:"maxAge,omitempty"`
+}
+
+// QueryPolicy configures the query load balancing policy.
+type QueryPolicy struct {
+	// Key is the name of the request query parameter to hash.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// A SecretKeyReference references a key of a Secret.
+type SecretKeyReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Secret. A namespaced ProxyRoute may only use Secrets
+	// in its own namespace, which is the default. Required for a cluster
+	// scoped ProxyRoute.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Key of the Secret to use.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
 }
 
 // HeaderOps defines header manipulation operations.
@@ -143,14 +501,38 @@ type HealthChecks struct {
 	// Passive defines passive health checks.
 	// +optional
 	Passive *PassiveHealthCheck `json:"passive,omitempty"`
+
+	// CircuitBreaker defines a circuit breaker.
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
 }
 
-// ActiveHealthCheck defines active health check configuration.
+// ActiveHealthCheck defines active health check configuration. Durations use
+// Go's duration syntax, e.g. "30s" or "1m30s".
+// +kubebuilder:validation:XValidation:rule="!(has(self.path) && has(self.uri))",message="path and uri are mutually exclusive"
 type ActiveHealthCheck struct {
-	// Path is the URI path to use for health checks.
+	// Path is the URI path to use for health checks. Use URI to also
+	// specify a query.
 	// +optional
 	Path *string `json:"path,omitempty"`
 
+	// URI is the URI, i.e. the path and optional query, to use for health
+	// checks, e.g. "/healthz?full=1".
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	URI *string `json:"uri,omitempty"`
+
+	// Port is the port to use for health checks, if it differs from the
+	// upstream's port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int `json:"port,omitempty"`
+
+	// Headers are the HTTP headers to set on health check requests.
+	// +optional
+	Headers map[string][]string `json:"headers,omitempty"`
+
 	// Interval is how often to perform active health checks.
 	// +optional
 	Interval *string `json:"interval,omitempty"`
@@ -158,17 +540,90 @@ type ActiveHealthCheck struct {
 	// Timeout is how long to wait for a response.
 	// +optional
 	Timeout *string `json:"timeout,omitempty"`
+
+	// Passes is how many consecutive health checks must pass before an
+	// unhealthy upstream is considered healthy again. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Passes *int `json:"passes,omitempty"`
+
+	// Fails is how many consecutive health checks must fail before a healthy
+	// upstream is considered unhealthy. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Fails *int `json:"fails,omitempty"`
+
+	// FollowRedirects makes health checks follow redirects, rather than
+	// treating them as the response.
+	// +optional
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+
+	// ExpectStatus is the HTTP status code a healthy upstream responds with.
+	// A single digit matches a class of codes, e.g. 2 matches any 2xx code.
+	// By default any 2xx code is healthy.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=599
+	// +optional
+	ExpectStatus *int `json:"expectStatus,omitempty"`
+
+	// ExpectBody is a regular expression, in Go's syntax, that the response
+	// body of a healthy upstream matches.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ExpectBody *string `json:"expectBody,omitempty"`
+
+	// MaxSize is the maximum number of bytes of the response body to read.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSize *int64 `json:"maxSize,omitempty"`
 }
 
-// PassiveHealthCheck defines passive health check configuration.
+// PassiveHealthCheck defines passive health check configuration. Passive
+// health checks judge upstreams by the requests they proxy. Caddy only counts
+// failed requests if FailDuration is set.
 type PassiveHealthCheck struct {
+	// FailDuration is how long a failed request counts against an upstream,
+	// in Caddy's duration syntax, e.g. "30s" or "1d".
+	// +optional
+	FailDuration *string `json:"failDuration,omitempty"`
+
 	// MaxFails is the maximum number of failed requests before marking unhealthy.
 	// +optional
 	MaxFails *int `json:"maxFails,omitempty"`
 
+	// UnhealthyStatus are the HTTP status codes of responses that count as
+	// failed requests.
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:Minimum=100
+	// +kubebuilder:validation:items:Maximum=599
+	// +optional
+	UnhealthyStatus []int `json:"unhealthyStatus,omitempty"`
+
 	// UnhealthyLatency is the latency threshold to consider unhealthy.
 	// +optional
 	UnhealthyLatency *string `json:"unhealthyLatency,omitempty"`
+
+	// UnhealthyRequestCount is the number of concurrent requests at which an
+	// upstream is considered unhealthy, and not sent more requests.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UnhealthyRequestCount *int `json:"unhealthyRequestCount,omitempty"`
+}
+
+// CircuitBreaker configures a circuit breaker, which stops proxying requests
+// to upstreams while they misbehave. Caddy has no built-in circuit breaker, so
+// it must be built with a circuit breaker module.
+type CircuitBreaker struct {
+	// Type is the name of the circuit breaker module, without the
+	// http.reverse_proxy.circuit_breakers. prefix of its ID.
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// Config is the configuration of the circuit breaker module, as it
+	// appears in Caddy's JSON config.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Config *runtime.RawExtension `json:"config,omitempty"`
 }
 
 // UpstreamTLS defines TLS settings for upstream connections.
@@ -186,15 +641,164 @@ type UpstreamTLS struct {
 	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
 }
 
+// HTTPTransport tunes how Caddy connects to upstreams over HTTP. Durations use
+// Caddy's duration syntax, e.g. "30s", "1m30s" or "1d".
+// +kubebuilder:validation:XValidation:rule="!has(self.versions) || !('3' in self.versions) || size(self.versions) == 1",message="version 3 can't be combined with other versions"
+type HTTPTransport struct {
+	// DialTimeout is how long to wait for a connection to an upstream to be
+	// established.
+	// +optional
+	DialTimeout *string `json:"dialTimeout,omitempty"`
+
+	// ResponseHeaderTimeout is how long to wait for an upstream to send the
+	// headers of its response after the request was written.
+	// +optional
+	ResponseHeaderTimeout *string `json:"responseHeaderTimeout,omitempty"`
+
+	// ReadTimeout is how long to wait for the next read from an upstream.
+	// +optional
+	ReadTimeout *string `json:"readTimeout,omitempty"`
+
+	// WriteTimeout is how long to wait for the next write to an upstream.
+	// +optional
+	WriteTimeout *string `json:"writeTimeout,omitempty"`
+
+	// KeepAlive configures how connections to upstreams are reused.
+	// +optional
+	KeepAlive *KeepAlive `json:"keepAlive,omitempty"`
+
+	// Versions are the HTTP versions to use with upstreams: "1.1", "2",
+	// "h2c" for HTTP/2 without TLS, as used by gRPC, or "3". Defaults to
+	// "1.1" and "2".
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=4
+	// +kubebuilder:validation:items:Enum="1.1";"2";"h2c";"3"
+	// +listType=set
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+
+	// MaxConnsPerHost is the maximum number of connections to each upstream,
+	// including connections in use. By default it is unlimited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnsPerHost *int `json:"maxConnsPerHost,omitempty"`
+
+	// ReadBufferSize is the size in bytes of the buffer used to read from
+	// upstreams.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReadBufferSize *int `json:"readBufferSize,omitempty"`
+
+	// WriteBufferSize is the size in bytes of the buffer used to write to
+	// upstreams.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WriteBufferSize *int `json:"writeBufferSize,omitempty"`
+
+	// ProxyProtocol sends the client's address to upstreams using the
+	// supplied version of the PROXY protocol.
+	// +kubebuilder:validation:Enum=v1;v2
+	// +optional
+	ProxyProtocol *string `json:"proxyProtocol,omitempty"`
+
+	// Resolvers are the addresses of the DNS servers used to resolve
+	// upstream addresses, e.g. "8.8.8.8:53". Defaults to the system
+	// resolver.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Resolvers []string `json:"resolvers,omitempty"`
+}
+
+// KeepAlive configures how connections to upstreams are reused.
+type KeepAlive struct {
+	// Enabled enables reusing connections. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ProbeInterval is how often to probe idle connections for liveness.
+	// +optional
+	ProbeInterval *string `json:"probeInterval,omitempty"`
+
+	// MaxIdleConns is the maximum number of idle connections across all
+	// upstreams.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxIdleConns *int `json:"maxIdleConns,omitempty"`
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections to each
+	// upstream.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxIdleConnsPerHost *int `json:"maxIdleConnsPerHost,omitempty"`
+
+	// IdleTimeout is how long an idle connection is kept open.
+	// +optional
+	IdleTimeout *string `json:"idleTimeout,omitempty"`
+}
+
 // ProxyRouteObservation represents the observed state of a ProxyRoute.
 type ProxyRouteObservation struct {
 	// RouteID is the ID assigned by Caddy to this route.
 	// +optional
 	RouteID string `json:"routeId,omitempty"`
 
-	// UpstreamStatuses contains the health status of upstreams.
+	// Position is the index of this route in its server's routes array.
+	// Caddy evaluates routes in order.
+	// +optional
+	Position *int `json:"position,omitempty"`
+
+	// UpstreamStatuses contains the health status Caddy reports for the
+	// upstreams declared by this route.
 	// +optional
 	UpstreamStatuses []UpstreamStatus `json:"upstreamStatuses,omitempty"`
+
+	// HealthyUpstreams is the number of declared upstreams Caddy reports as
+	// healthy. Without passive health checks every upstream Caddy reports
+	// counts as healthy, as Caddy proxies requests to all of them.
+	// +optional
+	HealthyUpstreams int `json:"healthyUpstreams"`
+
+	// TotalUpstreams is the number of distinct upstreams declared by this
+	// route.
+	// +optional
+	TotalUpstreams int `json:"totalUpstreams"`
+
+	// UpstreamSummary summarizes the health of the declared upstreams as
+	// "<healthy>/<total>".
+	// +optional
+	UpstreamSummary string `json:"upstreamSummary,omitempty"`
+
+	// Canary is the observed state of the route's canary group.
+	// +optional
+	Canary *CanaryObservation `json:"canary,omitempty"`
+}
+
+// Phases of a progressive canary.
+const (
+	CanaryPhaseProgressing = "Progressing"
+	CanaryPhasePromoted    = "Promoted"
+	CanaryPhaseRolledBack  = "RolledBack"
+)
+
+// CanaryObservation represents the observed state of a canary group.
+type CanaryObservation struct {
+	// Revision identifies the canary group's upstreams. Progressive shifting
+	// starts over when it changes.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Weight is the percentage of traffic currently sent to the canary
+	// group.
+	Weight int `json:"weight"`
+
+	// Phase of a progressive canary: Progressing, Promoted, or RolledBack.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// LastStepTime is when traffic was last shifted to or from a progressive
+	// canary.
+	// +optional
+	LastStepTime *metav1.Time `json:"lastStepTime,omitempty"`
 }
 
 // UpstreamStatus represents the health status of an upstream.
@@ -202,8 +806,16 @@ type UpstreamStatus struct {
 	// Address is the upstream address.
 	Address string `json:"address"`
 
-	// Healthy indicates if the upstream is healthy.
-	Healthy bool `json:"healthy"`
+	// Healthy indicates if the upstream is healthy, i.e. if its failed
+	// requests haven't reached the passive health checks' maxFails. It is
+	// unset if the route has no passive health checks with a failDuration,
+	// without which Caddy doesn't count failed requests.
+	// +optional
+	Healthy *bool `json:"healthy,omitempty"`
+
+	// Fails is the number of failed requests Caddy's passive health checks
+	// currently count against this upstream.
+	Fails int `json:"fails"`
 
 	// NumRequests is the number of active requests to this upstream.
 	NumRequests int `json:"numRequests"`
@@ -227,6 +839,7 @@ type ProxyRouteStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="UPSTREAMS",type="string",JSONPath=".status.atProvider.upstreamSummary"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,caddy}
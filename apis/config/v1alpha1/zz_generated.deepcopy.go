@@ -9,7 +9,8 @@
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -20,6 +21,32 @@ func (in *ActiveHealthCheck) DeepCopyInto(out *ActiveHealthCheck) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.URI != nil {
+		in, out := &in.URI, &out.URI
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Interval != nil {
 		in, out := &in.Interval, &out.Interval
 		*out = new(string)
@@ -30,6 +57,36 @@ func (in *ActiveHealthCheck) DeepCopyInto(out *ActiveHealthCheck) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Passes != nil {
+		in, out := &in.Passes, &out.Passes
+		*out = new(int)
+		**out = **in
+	}
+	if in.Fails != nil {
+		in, out := &in.Fails, &out.Fails
+		*out = new(int)
+		**out = **in
+	}
+	if in.FollowRedirects != nil {
+		in, out := &in.FollowRedirects, &out.FollowRedirects
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExpectStatus != nil {
+		in, out := &in.ExpectStatus, &out.ExpectStatus
+		*out = new(int)
+		**out = **in
+	}
+	if in.ExpectBody != nil {
+		in, out := &in.ExpectBody, &out.ExpectBody
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveHealthCheck.
@@ -42,6 +99,224 @@ func (in *ActiveHealthCheck) DeepCopy() *ActiveHealthCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Canary) DeepCopyInto(out *Canary) {
+	*out = *in
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make([]Upstream, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpstreamsFrom != nil {
+		in, out := &in.UpstreamsFrom, &out.UpstreamsFrom
+		*out = make([]UpstreamSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	if in.Progressive != nil {
+		in, out := &in.Progressive, &out.Progressive
+		*out = new(ProgressiveCanary)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Canary.
+func (in *Canary) DeepCopy() *Canary {
+	if in == nil {
+		return nil
+	}
+	out := new(Canary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryObservation) DeepCopyInto(out *CanaryObservation) {
+	*out = *in
+	if in.LastStepTime != nil {
+		in, out := &in.LastStepTime, &out.LastStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryObservation.
+func (in *CanaryObservation) DeepCopy() *CanaryObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreaker.
+func (in *CircuitBreaker) DeepCopy() *CircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CookiePolicy) DeepCopyInto(out *CookiePolicy) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretKeyReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CookiePolicy.
+func (in *CookiePolicy) DeepCopy() *CookiePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CookiePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynamicUpstreams) DeepCopyInto(out *DynamicUpstreams) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+	if in.Refresh != nil {
+		in, out := &in.Refresh, &out.Refresh
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resolvers != nil {
+		in, out := &in.Resolvers, &out.Resolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DialTimeout != nil {
+		in, out := &in.DialTimeout, &out.DialTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.DialFallbackDelay != nil {
+		in, out := &in.DialFallbackDelay, &out.DialFallbackDelay
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynamicUpstreams.
+func (in *DynamicUpstreams) DeepCopy() *DynamicUpstreams {
+	if in == nil {
+		return nil
+	}
+	out := new(DynamicUpstreams)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTransport) DeepCopyInto(out *HTTPTransport) {
+	*out = *in
+	if in.DialTimeout != nil {
+		in, out := &in.DialTimeout, &out.DialTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.ResponseHeaderTimeout != nil {
+		in, out := &in.ResponseHeaderTimeout, &out.ResponseHeaderTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadTimeout != nil {
+		in, out := &in.ReadTimeout, &out.ReadTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.WriteTimeout != nil {
+		in, out := &in.WriteTimeout, &out.WriteTimeout
+		*out = new(string)
+		**out = **in
+	}
+	if in.KeepAlive != nil {
+		in, out := &in.KeepAlive, &out.KeepAlive
+		*out = new(KeepAlive)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxConnsPerHost != nil {
+		in, out := &in.MaxConnsPerHost, &out.MaxConnsPerHost
+		*out = new(int)
+		**out = **in
+	}
+	if in.ReadBufferSize != nil {
+		in, out := &in.ReadBufferSize, &out.ReadBufferSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.WriteBufferSize != nil {
+		in, out := &in.WriteBufferSize, &out.WriteBufferSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resolvers != nil {
+		in, out := &in.Resolvers, &out.Resolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTransport.
+func (in *HTTPTransport) DeepCopy() *HTTPTransport {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTransport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HeaderManipulation) DeepCopyInto(out *HeaderManipulation) {
 	*out = *in
@@ -119,6 +394,21 @@ func (in *HeaderOps) DeepCopy() *HeaderOps {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderPolicy) DeepCopyInto(out *HeaderPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderPolicy.
+func (in *HeaderPolicy) DeepCopy() *HeaderPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthChecks) DeepCopyInto(out *HealthChecks) {
 	*out = *in
@@ -132,6 +422,11 @@ func (in *HealthChecks) DeepCopyInto(out *HealthChecks) {
 		*out = new(PassiveHealthCheck)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthChecks.
@@ -144,6 +439,46 @@ func (in *HealthChecks) DeepCopy() *HealthChecks {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeepAlive) DeepCopyInto(out *KeepAlive) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProbeInterval != nil {
+		in, out := &in.ProbeInterval, &out.ProbeInterval
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxIdleConns != nil {
+		in, out := &in.MaxIdleConns, &out.MaxIdleConns
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxIdleConnsPerHost != nil {
+		in, out := &in.MaxIdleConnsPerHost, &out.MaxIdleConnsPerHost
+		*out = new(int)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeepAlive.
+func (in *KeepAlive) DeepCopy() *KeepAlive {
+	if in == nil {
+		return nil
+	}
+	out := new(KeepAlive)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancing) DeepCopyInto(out *LoadBalancing) {
 	*out = *in
@@ -152,6 +487,26 @@ func (in *LoadBalancing) DeepCopyInto(out *LoadBalancing) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = new(HeaderPolicy)
+		**out = **in
+	}
+	if in.Cookie != nil {
+		in, out := &in.Cookie, &out.Cookie
+		*out = new(CookiePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = new(QueryPolicy)
+		**out = **in
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(string)
+		**out = **in
+	}
 	if in.TryDuration != nil {
 		in, out := &in.TryDuration, &out.TryDuration
 		*out = new(string)
@@ -162,6 +517,18 @@ func (in *LoadBalancing) DeepCopyInto(out *LoadBalancing) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+	if in.RetryMatch != nil {
+		in, out := &in.RetryMatch, &out.RetryMatch
+		*out = make([]RouteMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancing.
@@ -174,19 +541,128 @@ func (in *LoadBalancing) DeepCopy() *LoadBalancing {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchConditions) DeepCopyInto(out *MatchConditions) {
+	*out = *in
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Method != nil {
+		in, out := &in.Method, &out.Method
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.RemoteIP != nil {
+		in, out := &in.RemoteIP, &out.RemoteIP
+		*out = make([]IPRange, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientIP != nil {
+		in, out := &in.ClientIP, &out.ClientIP
+		*out = make([]IPRange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(string)
+		**out = **in
+	}
+	if in.PathRegexp != nil {
+		in, out := &in.PathRegexp, &out.PathRegexp
+		*out = new(RegexpMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeaderRegexp != nil {
+		in, out := &in.HeaderRegexp, &out.HeaderRegexp
+		*out = make(map[string]RegexpMatch, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Expression != nil {
+		in, out := &in.Expression, &out.Expression
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchConditions.
+func (in *MatchConditions) DeepCopy() *MatchConditions {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchConditions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PassiveHealthCheck) DeepCopyInto(out *PassiveHealthCheck) {
 	*out = *in
+	if in.FailDuration != nil {
+		in, out := &in.FailDuration, &out.FailDuration
+		*out = new(string)
+		**out = **in
+	}
 	if in.MaxFails != nil {
 		in, out := &in.MaxFails, &out.MaxFails
 		*out = new(int)
 		**out = **in
 	}
+	if in.UnhealthyStatus != nil {
+		in, out := &in.UnhealthyStatus, &out.UnhealthyStatus
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 	if in.UnhealthyLatency != nil {
 		in, out := &in.UnhealthyLatency, &out.UnhealthyLatency
 		*out = new(string)
 		**out = **in
 	}
+	if in.UnhealthyRequestCount != nil {
+		in, out := &in.UnhealthyRequestCount, &out.UnhealthyRequestCount
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PassiveHealthCheck.
@@ -199,6 +675,36 @@ func (in *PassiveHealthCheck) DeepCopy() *PassiveHealthCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressiveCanary) DeepCopyInto(out *ProgressiveCanary) {
+	*out = *in
+	if in.StepWeight != nil {
+		in, out := &in.StepWeight, &out.StepWeight
+		*out = new(int)
+		**out = **in
+	}
+	if in.StepInterval != nil {
+		in, out := &in.StepInterval, &out.StepInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxWeight != nil {
+		in, out := &in.MaxWeight, &out.MaxWeight
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressiveCanary.
+func (in *ProgressiveCanary) DeepCopy() *ProgressiveCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressiveCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyRoute) DeepCopyInto(out *ProxyRoute) {
 	*out = *in
@@ -261,10 +767,22 @@ func (in *ProxyRouteList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyRouteObservation) DeepCopyInto(out *ProxyRouteObservation) {
 	*out = *in
+	if in.Position != nil {
+		in, out := &in.Position, &out.Position
+		*out = new(int)
+		**out = **in
+	}
 	if in.UpstreamStatuses != nil {
 		in, out := &in.UpstreamStatuses, &out.UpstreamStatuses
 		*out = make([]UpstreamStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryObservation)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -281,16 +799,33 @@ func (in *ProxyRouteObservation) DeepCopy() *ProxyRouteObservation {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyRouteParameters) DeepCopyInto(out *ProxyRouteParameters) {
 	*out = *in
+	if in.CaddyEndpoint != nil {
+		in, out := &in.CaddyEndpoint, &out.CaddyEndpoint
+		*out = new(string)
+		**out = **in
+	}
 	if in.ServerName != nil {
 		in, out := &in.ServerName, &out.ServerName
 		*out = new(string)
 		**out = **in
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int)
+		**out = **in
+	}
 	if in.Match != nil {
 		in, out := &in.Match, &out.Match
 		*out = new(RouteMatch)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MatchSets != nil {
+		in, out := &in.MatchSets, &out.MatchSets
+		*out = make([]RouteMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Upstreams != nil {
 		in, out := &in.Upstreams, &out.Upstreams
 		*out = make([]Upstream, len(*in))
@@ -298,6 +833,23 @@ func (in *ProxyRouteParameters) DeepCopyInto(out *ProxyRouteParameters) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UpstreamsFrom != nil {
+		in, out := &in.UpstreamsFrom, &out.UpstreamsFrom
+		*out = make([]UpstreamSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DynamicUpstreams != nil {
+		in, out := &in.DynamicUpstreams, &out.DynamicUpstreams
+		*out = new(DynamicUpstreams)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(Canary)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.LoadBalancing != nil {
 		in, out := &in.LoadBalancing, &out.LoadBalancing
 		*out = new(LoadBalancing)
@@ -318,6 +870,16 @@ func (in *ProxyRouteParameters) DeepCopyInto(out *ProxyRouteParameters) {
 		*out = new(UpstreamTLS)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(HTTPTransport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinHealthyUpstreams != nil {
+		in, out := &in.MinHealthyUpstreams, &out.MinHealthyUpstreams
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyRouteParameters.
@@ -365,37 +927,49 @@ func (in *ProxyRouteStatus) DeepCopy() *ProxyRouteStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RouteMatch) DeepCopyInto(out *RouteMatch) {
+func (in *QueryPolicy) DeepCopyInto(out *QueryPolicy) {
 	*out = *in
-	if in.Host != nil {
-		in, out := &in.Host, &out.Host
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryPolicy.
+func (in *QueryPolicy) DeepCopy() *QueryPolicy {
+	if in == nil {
+		return nil
 	}
-	if in.Path != nil {
-		in, out := &in.Path, &out.Path
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out := new(QueryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegexpMatch) DeepCopyInto(out *RegexpMatch) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
 	}
-	if in.Method != nil {
-		in, out := &in.Method, &out.Method
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegexpMatch.
+func (in *RegexpMatch) DeepCopy() *RegexpMatch {
+	if in == nil {
+		return nil
 	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string][]string, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+	out := new(RegexpMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMatch) DeepCopyInto(out *RouteMatch) {
+	*out = *in
+	in.MatchConditions.DeepCopyInto(&out.MatchConditions)
+	if in.Not != nil {
+		in, out := &in.Not, &out.Not
+		*out = make([]MatchConditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
@@ -410,6 +984,51 @@ func (in *RouteMatch) DeepCopy() *RouteMatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceUpstreams) DeepCopyInto(out *ServiceUpstreams) {
+	*out = *in
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceUpstreams.
+func (in *ServiceUpstreams) DeepCopy() *ServiceUpstreams {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceUpstreams)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Upstream) DeepCopyInto(out *Upstream) {
 	*out = *in
@@ -418,6 +1037,11 @@ func (in *Upstream) DeepCopyInto(out *Upstream) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Upstream.
@@ -430,9 +1054,30 @@ func (in *Upstream) DeepCopy() *Upstream {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamSource) DeepCopyInto(out *UpstreamSource) {
+	*out = *in
+	in.Service.DeepCopyInto(&out.Service)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamSource.
+func (in *UpstreamSource) DeepCopy() *UpstreamSource {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamStatus) DeepCopyInto(out *UpstreamStatus) {
 	*out = *in
+	if in.Healthy != nil {
+		in, out := &in.Healthy, &out.Healthy
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamStatus.
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+	xpv2 "github.com/crossplane/crossplane-runtime/v2/apis/common/v2"
+
+	configv1alpha1 "github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+)
+
+// A ProxyRouteSpec defines the desired state of a ProxyRoute.
+type ProxyRouteSpec struct {
+	xpv2.ManagedResourceSpec `json:",inline"`
+
+	// ForProvider defines the desired state of the Caddy route. A namespaced
+	// ProxyRoute always uses the admin API endpoint of its ProviderConfig.
+	// +kubebuilder:validation:XValidation:rule="!has(self.caddyEndpoint)",message="caddyEndpoint cannot be set on a namespaced ProxyRoute; set the endpoint in its ProviderConfig"
+	ForProvider configv1alpha1.ProxyRouteParameters `json:"forProvider"`
+}
+
+// A ProxyRouteStatus represents the observed state of a ProxyRoute.
+type ProxyRouteStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          configv1alpha1.ProxyRouteObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProxyRoute configures a Caddy reverse proxy route. It references a
+// ProviderConfig in its own namespace, or a ClusterProviderConfig.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="UPSTREAMS",type="string",JSONPath=".status.atProvider.upstreamSummary"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,caddy}
+type ProxyRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProxyRouteSpec   `json:"spec"`
+	Status ProxyRouteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProxyRouteList contains a list of ProxyRoute
+type ProxyRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProxyRoute `json:"items"`
+}
+
+// ProxyRoute type metadata.
+var (
+	ProxyRouteKindAPIVersion = ProxyRouteKind + "." + SchemeGroupVersion.String()
+)
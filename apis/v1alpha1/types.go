@@ -11,7 +11,11 @@ type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
 }
 
-// ProviderCredentials required to authenticate.
+// ProviderCredentials required to authenticate to the Caddy admin API. Use
+// source None for an admin API that does not require authentication.
+// Otherwise the credentials must be a JSON object containing either a bearer
+// token, e.g. {"token": "..."}, or a basic auth pair, e.g.
+// {"username": "...", "password": "..."}.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
 	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem
@@ -20,9 +24,82 @@ type ProviderCredentials struct {
 	xpv1.CommonCredentialSelectors `json:",inline"`
 }
 
+// A ProviderConfigSpec defines the desired state of a ProviderConfig.
 type ProviderConfigSpec struct {
+	// Endpoint is the Caddy admin API endpoint, either a URL (e.g.,
+	// "http://caddy:2019") or a Unix domain socket in Caddy's network address
+	// syntax (e.g., "unix//run/caddy/admin.sock"). It is required unless
+	// every cluster scoped ProxyRoute that uses the ProviderConfig specifies
+	// its own caddyEndpoint, as ProxyRoutes did before endpoints were
+	// configured here.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// TLS configures how connections to an HTTPS admin API endpoint are
+	// secured.
+	// +optional
+	TLS *AdminTLS `json:"tls,omitempty"`
+
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// Timeout is how long to wait for each attempt at a request to the admin
+	// API. Defaults to the provider's --caddy-timeout flag.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxRetries is how many times a request that failed because the admin
+	// API was unreachable or returned a server error is retried. Only
+	// requests that are safe to repeat are retried. Defaults to the
+	// provider's --caddy-max-retries flag.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// RetryBackoff is the delay before the first retry of a failed request.
+	// It doubles with each subsequent retry, and is jittered. Zero retries
+	// immediately. Defaults to the provider's --caddy-retry-backoff flag.
+	// +optional
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+
+	// RouteOrdering determines where ProxyRoutes place their routes in a
+	// server's routes array, which Caddy evaluates in order. With Priority,
+	// routes with a higher spec.forProvider.priority are evaluated first.
+	// With Specificity, routes of equal priority are also ordered by their
+	// match conditions: routes that match on host before those that don't,
+	// then exact paths before path patterns before catch-alls. Routes that
+	// rank equally are evaluated in the order they were created. Defaults to
+	// Priority.
+	// +kubebuilder:validation:Enum=Priority;Specificity
+	// +optional
+	RouteOrdering *string `json:"routeOrdering,omitempty"`
+}
+
+// Route orderings.
+const (
+	RouteOrderingPriority    = "Priority"
+	RouteOrderingSpecificity = "Specificity"
+)
+
+// AdminTLS configures TLS for connections to the Caddy admin API.
+type AdminTLS struct {
+	// ServerName overrides the server name used to verify the admin API's
+	// certificate. Defaults to the host of the endpoint.
+	// +optional
+	ServerName *string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify disables verification of the admin API's
+	// certificate.
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+
+	// SecretRef references a Secret containing the TLS material used to
+	// connect to Caddy's remote admin endpoint. The client certificate and
+	// key under "tls.crt" and "tls.key" are presented to the admin API,
+	// whose certificate must be issued by a CA in the bundle under "ca.crt"
+	// rather than the system roots. All keys are optional.
+	// +optional
+	SecretRef *xpv1.SecretReference `json:"secretRef,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -30,6 +107,7 @@ type ProviderConfigSpec struct {
 
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".spec.endpoint"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:resource:scope=Namespaced,categories={crossplane,provider,caddy}
 // A ProviderConfig configures a Helm 'provider', i.e. a connection to a particular
@@ -79,6 +157,7 @@ type ProviderConfigUsageList struct {
 
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="ENDPOINT",type="string",JSONPath=".spec.endpoint"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,caddy}
 // A ClusterProviderConfig configures a Caddy provider.
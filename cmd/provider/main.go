@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -45,6 +46,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/crossplane/provider-caddy/apis"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
 	caddy "github.com/crossplane/provider-caddy/internal/controller"
 	"github.com/crossplane/provider-caddy/internal/version"
 )
@@ -61,6 +63,10 @@ func main() {
 
 		maxReconcileRate = app.Flag("max-reconcile-rate", "The global maximum rate per second at which resources may checked for drift from the desired state.").Default("10").Int()
 
+		caddyTimeout      = app.Flag("caddy-timeout", "How long to wait for each attempt at a request to a Caddy admin API. ProviderConfigs may override this.").Default(caddyclient.DefaultRequestOptions.Timeout.String()).Duration()
+		caddyMaxRetries   = app.Flag("caddy-max-retries", "How many times to retry a request to a Caddy admin API that failed transiently. ProviderConfigs may override this.").Default(strconv.Itoa(caddyclient.DefaultRequestOptions.MaxRetries)).Int()
+		caddyRetryBackoff = app.Flag("caddy-retry-backoff", "The delay before the first retry of a failed request to a Caddy admin API. ProviderConfigs may override this.").Default(caddyclient.DefaultRequestOptions.RetryBackoff.String()).Duration()
+
 		enableManagementPolicies = app.Flag("enable-management-policies", "Enable support for Management Policies.").Default("true").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
 		enableChangeLogs         = app.Flag("enable-changelogs", "Enable support for capturing change logs during reconciliation.").Default("false").Envar("ENABLE_CHANGE_LOGS").Bool()
 		changelogsSocketPath     = app.Flag("changelogs-socket-path", "Path for changelogs socket (if enabled)").Default("/var/run/changelogs/changelogs.sock").Envar("CHANGELOGS_SOCKET_PATH").String()
@@ -149,6 +155,11 @@ func main() {
 	}
 
 	kingpin.FatalIfError(customresourcesgate.Setup(mgr, o), "Cannot setup CRD gate controller")
-	kingpin.FatalIfError(caddy.SetupGated(mgr, o), "Cannot setup Caddy controllers")
+	ro := caddyclient.RequestOptions{
+		Timeout:      *caddyTimeout,
+		MaxRetries:   *caddyMaxRetries,
+		RetryBackoff: *caddyRetryBackoff,
+	}
+	kingpin.FatalIfError(caddy.SetupGated(mgr, o, ro), "Cannot setup Caddy controllers")
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
@@ -20,29 +20,207 @@ package caddy
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// unixSocketEndpoint is the base URL of requests sent over a Unix socket. Its
+// host is sent as the Host header, and is not used to dial.
+const unixSocketEndpoint = "http://127.0.0.1"
+
+// maxRetryBackoff caps the delay between retries of a failed request.
+const maxRetryBackoff = 10 * time.Second
+
+// maxConflictRetries is how many times a read-modify-write cycle is attempted
+// when the config changes between reading and writing it.
+const maxConflictRetries = 5
+
 // Client is a client for the Caddy admin API.
 type Client struct {
-	endpoint   string
-	httpClient *http.Client
+	endpoint    string
+	transport   *http.Transport
+	httpClient  *http.Client
+	credentials Credentials
+	requests    RequestOptions
+}
+
+// An Option configures a Client.
+type Option func(c *Client) error
+
+// TLSOptions configure TLS for connections to an HTTPS admin API endpoint.
+type TLSOptions struct {
+	// ServerName overrides the server name used to verify the admin API's
+	// certificate.
+	ServerName string
+
+	// InsecureSkipVerify disables verification of the admin API's
+	// certificate.
+	InsecureSkipVerify bool
+
+	// CAPEM is a PEM encoded bundle of the CAs the admin API's certificate
+	// must be issued by. When set the system roots are not trusted.
+	CAPEM []byte
+
+	// CertPEM and KeyPEM are the PEM encoded client certificate and key
+	// presented to an admin API that authenticates clients by certificate,
+	// such as Caddy's remote admin endpoint.
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// WithTLS configures TLS for connections to the admin API. It returns
+// ErrInvalidTLSConfig if the CA bundle or client certificate can't be parsed.
+func WithTLS(o TLSOptions) Option {
+	return func(c *Client) error {
+		cfg := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         o.ServerName,
+			InsecureSkipVerify: o.InsecureSkipVerify, //nolint:gosec // Explicitly requested by the ProviderConfig.
+		}
+
+		if len(o.CAPEM) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(o.CAPEM) {
+				return fmt.Errorf("%w: failed to parse CA bundle: no PEM encoded certificates found", ErrInvalidTLSConfig)
+			}
+			cfg.RootCAs = pool
+		}
+
+		if len(o.CertPEM) > 0 || len(o.KeyPEM) > 0 {
+			cert, err := tls.X509KeyPair(o.CertPEM, o.KeyPEM)
+			if err != nil {
+				return fmt.Errorf("%w: failed to load client certificate: %w", ErrInvalidTLSConfig, err)
+			}
+			cfg.Certificates = []tls.Certificate{cert}
+		}
+
+		c.transport.TLSClientConfig = cfg
+		return nil
+	}
+}
+
+// Credentials authenticate requests to an admin API that sits behind an
+// authenticating proxy. They are supplied as JSON, e.g. {"token": "..."} or
+// {"username": "...", "password": "..."}.
+type Credentials struct {
+	// Token is sent as a bearer token.
+	Token string `json:"token,omitempty"`
+
+	// Username and Password are sent using HTTP basic authentication.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
 }
 
-// NewClient creates a new Caddy API client.
-func NewClient(endpoint string) *Client {
-	return &Client{
+// ParseCredentials parses the supplied JSON encoded credentials.
+func ParseCredentials(data []byte) (Credentials, error) {
+	creds := Credentials{}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if creds.Token == "" && creds.Username == "" {
+		return Credentials{}, errors.New("credentials must contain either a token or a username")
+	}
+	if creds.Token != "" && creds.Username != "" {
+		return Credentials{}, errors.New("credentials must not contain both a token and a username")
+	}
+	return creds, nil
+}
+
+// WithCredentials authenticates every request to the admin API with the
+// supplied credentials.
+func WithCredentials(creds Credentials) Option {
+	return func(c *Client) error {
+		c.credentials = creds
+		return nil
+	}
+}
+
+// RequestOptions configure how requests to the admin API are sent.
+type RequestOptions struct {
+	// Timeout is how long to wait for each attempt at a request. Zero means
+	// no timeout.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a request that failed with ErrUnavailable
+	// is retried. Only requests that are safe to repeat are retried.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry. It doubles with each
+	// subsequent retry. Zero means retry immediately.
+	RetryBackoff time.Duration
+}
+
+// DefaultRequestOptions are used unless overridden using WithRequestOptions.
+var DefaultRequestOptions = RequestOptions{
+	Timeout:      30 * time.Second,
+	MaxRetries:   3,
+	RetryBackoff: 250 * time.Millisecond,
+}
+
+// WithRequestOptions configures how requests to the admin API are sent.
+func WithRequestOptions(o RequestOptions) Option {
+	return func(c *Client) error {
+		if o.Timeout < 0 || o.MaxRetries < 0 || o.RetryBackoff < 0 {
+			return errors.New("request timeout, retries and retry backoff must not be negative")
+		}
+		c.requests = o
+		c.httpClient.Timeout = o.Timeout
+		return nil
+	}
+}
+
+// NewClient creates a new Caddy API client. The endpoint is either an HTTP(S)
+// URL or a Unix domain socket in Caddy's network address syntax, e.g.
+// "unix//run/caddy/admin.sock".
+func NewClient(endpoint string, opts ...Option) (*Client, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // DefaultTransport is always an *http.Transport.
+	c := &Client{
 		endpoint:   strings.TrimSuffix(endpoint, "/"),
-		httpClient: &http.Client{},
+		transport:  t,
+		httpClient: &http.Client{Transport: t, Timeout: DefaultRequestOptions.Timeout},
+		requests:   DefaultRequestOptions,
 	}
+
+	if socket, ok := unixSocketPath(endpoint); ok {
+		d := &net.Dialer{}
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// Caddy only accepts an empty Host header or a loopback IP on its
+		// Unix socket admin endpoint, and Go won't send an empty one.
+		c.endpoint = unixSocketEndpoint
+	}
+	for _, o := range opts {
+		if err := o(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CloseIdleConnections closes any connections to the admin API that are not
+// in use. Each Client has its own connection pool, so a Client that is no
+// longer needed should be closed to release its connections promptly.
+func (c *Client) CloseIdleConnections() {
+	c.transport.CloseIdleConnections()
 }
 
 // ProxyRoute represents a Caddy reverse proxy route configuration.
 type ProxyRoute struct {
+	ID       string     `json:"@id,omitempty"`
 	Match    []MatchSet `json:"match,omitempty"`
 	Handle   []Handler  `json:"handle"`
 	Terminal bool       `json:"terminal,omitempty"`
@@ -50,21 +228,46 @@ type ProxyRoute struct {
 
 // MatchSet represents a set of matchers (Caddy uses array of matcher sets).
 type MatchSet struct {
-	Host   []string            `json:"host,omitempty"`
-	Path   []string            `json:"path,omitempty"`
-	Method []string            `json:"method,omitempty"`
-	Header map[string][]string `json:"header,omitempty"`
+	Host         []string            `json:"host,omitempty"`
+	Path         []string            `json:"path,omitempty"`
+	Method       []string            `json:"method,omitempty"`
+	Header       map[string][]string `json:"header,omitempty"`
+	Query        map[string][]string `json:"query,omitempty"`
+	RemoteIP     *IPRanges           `json:"remote_ip,omitempty"`
+	ClientIP     *IPRanges           `json:"client_ip,omitempty"`
+	Protocol     string              `json:"protocol,omitempty"`
+	PathRegexp   *Regexp             `json:"path_regexp,omitempty"`
+	HeaderRegexp map[string]Regexp   `json:"header_regexp,omitempty"`
+	Not          []MatchSet          `json:"not,omitempty"`
+	Expression   string              `json:"expression,omitempty"`
+}
+
+// IPRanges represents the IP addresses and CIDR ranges of a remote_ip or
+// client_ip matcher.
+type IPRanges struct {
+	Ranges []string `json:"ranges"`
+}
+
+// Regexp represents a regular expression matcher.
+type Regexp struct {
+	Name    string `json:"name,omitempty"`
+	Pattern string `json:"pattern"`
 }
 
 // Handler represents a route handler.
 type Handler struct {
-	Handler       string         `json:"handler"`
-	Routes        []Route        `json:"routes,omitempty"`
-	Upstreams     []Upstream     `json:"upstreams,omitempty"`
-	LoadBalancing *LoadBalancing `json:"load_balancing,omitempty"`
-	Headers       *Headers       `json:"headers,omitempty"`
-	HealthChecks  *HealthChecks  `json:"health_checks,omitempty"`
-	Transport     *Transport     `json:"transport,omitempty"`
+	Handler          string            `json:"handler"`
+	Routes           []Route           `json:"routes,omitempty"`
+	Upstreams        []Upstream        `json:"upstreams,omitempty"`
+	DynamicUpstreams *DynamicUpstreams `json:"dynamic_upstreams,omitempty"`
+	LoadBalancing    *LoadBalancing    `json:"load_balancing,omitempty"`
+	Headers          *Headers          `json:"headers,omitempty"`
+	HealthChecks     *HealthChecks     `json:"health_checks,omitempty"`
+	Transport        *Transport        `json:"transport,omitempty"`
+
+	// CircuitBreaker is the config of a circuit breaker module, including
+	// the "type" key that names it.
+	CircuitBreaker map[string]any `json:"circuit_breaker,omitempty"`
 }
 
 // Route represents a subroute.
@@ -78,16 +281,55 @@ type Upstream struct {
 	MaxRequests int    `json:"max_requests,omitempty"`
 }
 
+// DynamicUpstreams represents a source of upstreams that Caddy looks up
+// itself, i.e. the srv or a upstream source modules.
+type DynamicUpstreams struct {
+	Source            string    `json:"source"`
+	Name              string    `json:"name,omitempty"`
+	Port              string    `json:"port,omitempty"`
+	Refresh           string    `json:"refresh,omitempty"`
+	Resolver          *Resolver `json:"resolver,omitempty"`
+	DialTimeout       string    `json:"dial_timeout,omitempty"`
+	DialFallbackDelay string    `json:"dial_fallback_delay,omitempty"`
+}
+
+// Resolver represents the DNS servers used to look up dynamic upstreams.
+type Resolver struct {
+	Addresses []string `json:"addresses,omitempty"`
+}
+
 // LoadBalancing represents load balancing configuration.
 type LoadBalancing struct {
 	SelectionPolicy *SelectionPolicy `json:"selection_policy,omitempty"`
+	Retries         int              `json:"retries,omitempty"`
 	TryDuration     string           `json:"try_duration,omitempty"`
 	TryInterval     string           `json:"try_interval,omitempty"`
+	RetryMatch      []MatchSet       `json:"retry_match,omitempty"`
 }
 
-// SelectionPolicy represents a load balancing selection policy.
+// SelectionPolicy represents a load balancing selection policy. Which fields
+// apply depends on the policy.
 type SelectionPolicy struct {
 	Policy string `json:"policy,omitempty"`
+
+	// Weights are the relative weights of a route's upstreams, in order,
+	// used by the weighted_round_robin policy.
+	Weights []int `json:"weights,omitempty"`
+
+	// Field is the request header hashed by the header policy.
+	Field string `json:"field,omitempty"`
+
+	// Key is the query parameter hashed by the query policy.
+	Key string `json:"key,omitempty"`
+
+	// Name, Secret, and MaxAge configure the cookie policy's cookie.
+	Name   string `json:"name,omitempty"`
+	Secret string `json:"secret,omitempty"`
+	MaxAge string `json:"max_age,omitempty"`
+
+	// Fallback is the policy used by the header, cookie, and query policies
+	// when a request lacks what they hash.
+	Fallback *SelectionPolicy `json:"fallback,omitempty"`
 }
 
 // Headers represents header manipulation configuration.
@@ -111,21 +353,53 @@ type HealthChecks struct {
 
 // ActiveHealthCheck represents active health check configuration.
 type ActiveHealthCheck struct {
-	Path     string `json:"path,omitempty"`
-	Interval string `json:"interval,omitempty"`
-	Timeout  string `json:"timeout,omitempty"`
+	Path            string              `json:"path,omitempty"`
+	URI             string              `json:"uri,omitempty"`
+	Port            int                 `json:"port,omitempty"`
+	Headers         map[string][]string `json:"headers,omitempty"`
+	FollowRedirects bool                `json:"follow_redirects,omitempty"`
+	Interval        string              `json:"interval,omitempty"`
+	Timeout         string              `json:"timeout,omitempty"`
+	Passes          int                 `json:"passes,omitempty"`
+	Fails           int                 `json:"fails,omitempty"`
+	MaxSize         int64               `json:"max_size,omitempty"`
+	ExpectStatus    int                 `json:"expect_status,omitempty"`
+	ExpectBody      string              `json:"expect_body,omitempty"`
 }
 
 // PassiveHealthCheck represents passive health check configuration.
 type PassiveHealthCheck struct {
-	MaxFails         int    `json:"max_fails,omitempty"`
-	UnhealthyLatency string `json:"unhealthy_latency,omitempty"`
+	FailDuration          string `json:"fail_duration,omitempty"`
+	MaxFails              int    `json:"max_fails,omitempty"`
+	UnhealthyStatus       []int  `json:"unhealthy_status,omitempty"`
+	UnhealthyLatency      string `json:"unhealthy_latency,omitempty"`
+	UnhealthyRequestCount int    `json:"unhealthy_request_count,omitempty"`
 }
 
 // Transport represents upstream transport configuration.
 type Transport struct {
-	Protocol string     `json:"protocol"`
-	TLS      *TLSConfig `json:"tls,omitempty"`
+	Protocol              string     `json:"protocol"`
+	TLS                   *TLSConfig `json:"tls,omitempty"`
+	Resolver              *Resolver  `json:"resolver,omitempty"`
+	KeepAlive             *KeepAlive `json:"keep_alive,omitempty"`
+	DialTimeout           string     `json:"dial_timeout,omitempty"`
+	ResponseHeaderTimeout string     `json:"response_header_timeout,omitempty"`
+	ReadTimeout           string     `json:"read_timeout,omitempty"`
+	WriteTimeout          string     `json:"write_timeout,omitempty"`
+	Versions              []string   `json:"versions,omitempty"`
+	MaxConnsPerHost       int        `json:"max_conns_per_host,omitempty"`
+	ReadBufferSize        int        `json:"read_buffer_size,omitempty"`
+	WriteBufferSize       int        `json:"write_buffer_size,omitempty"`
+	ProxyProtocol         string     `json:"proxy_protocol,omitempty"`
+}
+
+// KeepAlive represents how an HTTP transport reuses connections.
+type KeepAlive struct {
+	Enabled             *bool  `json:"enabled,omitempty"`
+	ProbeInterval       string `json:"probe_interval,omitempty"`
+	MaxIdleConns        int    `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host,omitempty"`
+	IdleTimeout         string `json:"idle_timeout,omitempty"`
 }
 
 // TLSConfig represents TLS configuration for upstream connections.
@@ -134,202 +408,511 @@ type TLSConfig struct {
 	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
 }
 
-// UpstreamStatus represents the health status of an upstream.
+// maxDurationLength is the length of the longest duration string Caddy
+// parses.
+const maxDurationLength = 1024
+
+// ParseDuration parses a duration string the way Caddy does. Caddy's syntax
+// is Go's, plus a "d" unit of 24 hours, e.g. "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	if len(s) > maxDurationLength {
+		return 0, fmt.Errorf("duration %.16q... is longer than %d characters", s, maxDurationLength)
+	}
+	var b strings.Builder
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'd':
+			days, err := strconv.ParseFloat(s[start:i], 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid number of days in duration %q", s)
+			}
+			b.WriteString(strconv.FormatFloat(days*24, 'f', -1, 64))
+			b.WriteString("h")
+			start = i + 1
+		case c >= '0' && c <= '9' || c == '.' || c == '-' || c == '+':
+			// Part of a number.
+		default:
+			// Part of a unit, which ends any number that precedes it.
+			b.WriteString(s[start : i+1])
+			start = i + 1
+		}
+	}
+	b.WriteString(s[start:])
+	return time.ParseDuration(b.String())
+}
+
+// UpstreamStatus represents the status of an upstream, as reported by
+// /reverse_proxy/upstreams. Caddy doesn't report whether an upstream is
+// healthy, only how many failed requests its passive health checks currently
+// count against it.
 type UpstreamStatus struct {
 	Address     string `json:"address"`
-	Healthy     bool   `json:"healthy"`
 	NumRequests int    `json:"num_requests"`
+	Fails       int    `json:"fails"`
 }
 
-// CreateProxyRoute creates a new proxy route in Caddy.
-func (c *Client) CreateProxyRoute(ctx context.Context, serverName string, route *ProxyRoute) (string, error) {
-	path := fmt.Sprintf("/config/apps/http/servers/%s/routes", serverName)
-
-	body, err := json.Marshal(route)
+// An Order reports whether route a should be evaluated before route b. Caddy
+// evaluates a server's routes in the order they appear in its routes array.
+type Order func(a, b *ProxyRoute) bool
+
+// CreateProxyRoute creates a new proxy route in Caddy, tagged with the supplied
+// @id so that it can later be addressed independently of its match conditions
+// or its position in the server's routes array. The route is inserted before
+// the first route it should be evaluated before according to the supplied
+// Order, or appended if there is no such route or the Order is nil. If a route
+// with the supplied @id already exists, e.g. because a retried request was
+// applied but its response was lost, the route is considered created.
+func (c *Client) CreateProxyRoute(ctx context.Context, serverName, routeID string, route *ProxyRoute, order Order) error {
+	r := *route
+	r.ID = routeID
+
+	err := c.retryOnConflict(ctx, func() error {
+		_, routes, etag, err := c.readRoutes(ctx, serverName)
+		if err != nil {
+			return fmt.Errorf("failed to get routes: %w", err)
+		}
+		if slices.ContainsFunc(routes, func(existing ProxyRoute) bool { return existing.ID == routeID }) {
+			return nil
+		}
+		i := insertionIndex(routes, &r, order)
+		if i == len(routes) {
+			_, err = c.doIfMatch(ctx, http.MethodPost, routesPath(serverName), etag, &r, nil)
+			return err
+		}
+		_, err = c.doIfMatch(ctx, http.MethodPut, fmt.Sprintf("%s/%d", routesPath(serverName), i), etag, &r, nil)
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal route: %w", err)
+		return fmt.Errorf("failed to create route: %w", serverError(serverName, err))
 	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+// OrderProxyRoute moves the proxy route with the supplied @id to the position
+// it would be inserted at by CreateProxyRoute, unless it is already in order
+// according to RouteIndex, and returns its position. The server's routes
+// array is replaced by a single admin API call, so no request ever observes
+// the route missing. Other routes are not reordered.
+func (c *Client) OrderProxyRoute(ctx context.Context, serverName, routeID string, order Order) (int, error) {
+	var to int
+	err := c.retryOnConflict(ctx, func() error {
+		raw, routes, etag, err := c.readRoutes(ctx, serverName)
+		if err != nil {
+			return fmt.Errorf("failed to get routes: %w", err)
+		}
+		var from int
+		from, to = RouteIndex(routes, routeID, order)
+		if from < 0 {
+			return fmt.Errorf("%w: route %q is not a route of server %q", ErrNotFound, routeID, serverName)
+		}
+		if from == to {
+			return nil
+		}
 
-	resp, err := c.httpClient.Do(req)
+		own := raw[from]
+		reordered := make([]json.RawMessage, 0, len(raw))
+		reordered = append(reordered, raw[:from]...)
+		reordered = append(reordered, raw[from+1:]...)
+		reordered = append(reordered[:to], append([]json.RawMessage{own}, reordered[to:]...)...)
+		_, err = c.doIfMatch(ctx, http.MethodPatch, routesPath(serverName), etag, reordered, nil)
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create route: %w", err)
+		return 0, fmt.Errorf("failed to order route: %w", serverError(serverName, err))
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return to, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("caddy API returned status %d: %s", resp.StatusCode, string(body))
+// RouteIndex returns the index of the route with the supplied @id in routes,
+// and the index it should be at according to the supplied Order. The route
+// is in order, and both indexes are the same, if it should not be evaluated
+// before any route that precedes it and no route that follows it should be
+// evaluated before it. Routes that rank equally therefore stay where they
+// are. Otherwise the second index is the one CreateProxyRoute would insert
+// the route at if it were removed from routes and recreated. Both are -1 if
+// the route is not in routes.
+func RouteIndex(routes []ProxyRoute, routeID string, order Order) (current, ordered int) {
+	for i := range routes {
+		if routes[i].ID != routeID {
+			continue
+		}
+		if inOrder(routes, i, order) {
+			return i, i
+		}
+		rest := make([]ProxyRoute, 0, len(routes)-1)
+		rest = append(rest, routes[:i]...)
+		rest = append(rest, routes[i+1:]...)
+		return i, insertionIndex(rest, &routes[i], order)
 	}
+	return -1, -1
+}
 
-	// Caddy doesn't return a route ID, so we'll use the host+path as identifier
-	// In a production implementation, you might want to use @id directives
-	routeID := generateRouteID(route)
-	return routeID, nil
+// inOrder reports whether the route at the supplied index of routes is in
+// order relative to the other routes according to the supplied Order.
+func inOrder(routes []ProxyRoute, i int, order Order) bool {
+	if order == nil {
+		return true
+	}
+	for j := range routes {
+		switch {
+		case j < i && order(&routes[i], &routes[j]):
+			return false
+		case j > i && order(&routes[j], &routes[i]):
+			return false
+		}
+	}
+	return true
 }
 
-// UpdateProxyRoute updates an existing proxy route in Caddy.
-func (c *Client) UpdateProxyRoute(ctx context.Context, serverName, routeID string, route *ProxyRoute) error {
-	// For simplicity, we'll delete and recreate the route
-	// In production, you'd want to use Caddy's PATCH endpoint or @id directives
-	if err := c.DeleteProxyRoute(ctx, serverName, routeID); err != nil {
-		return fmt.Errorf("failed to delete old route: %w", err)
+// insertionIndex returns the index of the first of the supplied routes that
+// route should be evaluated before according to the supplied Order, or
+// len(routes) if there is none.
+func insertionIndex(routes []ProxyRoute, route *ProxyRoute, order Order) int {
+	if order == nil {
+		return len(routes)
+	}
+	for i := range routes {
+		if order(route, &routes[i]) {
+			return i
+		}
 	}
+	return len(routes)
+}
 
-	_, err := c.CreateProxyRoute(ctx, serverName, route)
+// UpdateProxyRoute replaces the proxy route with the supplied @id in place.
+// The route keeps its position in the server's routes array, and because the
+// replacement is a single admin API call no request ever observes the route
+// missing.
+func (c *Client) UpdateProxyRoute(ctx context.Context, routeID string, route *ProxyRoute) error {
+	r := *route
+	r.ID = routeID
+
+	err := c.retryOnConflict(ctx, func() error {
+		etag, err := c.doIfMatch(ctx, http.MethodGet, idPath(routeID), "", nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get route: %w", err)
+		}
+		_, err = c.doIfMatch(ctx, http.MethodPatch, idPath(routeID), etag, &r, nil)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create updated route: %w", err)
+		return fmt.Errorf("failed to update route: %w", err)
 	}
-
 	return nil
 }
 
-// DeleteProxyRoute deletes a proxy route from Caddy.
-//
-//nolint:gocyclo // Complex due to multi-step deletion process
-func (c *Client) DeleteProxyRoute(ctx context.Context, serverName, routeID string) error {
-	// This is a simplified implementation
-	// In production, you'd use Caddy's array removal or @id directives
-	path := fmt.Sprintf("/config/apps/http/servers/%s/routes", serverName)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// DeleteProxyRoute deletes the proxy route with the supplied @id from Caddy.
+// Deleting a route that does not exist is not an error.
+func (c *Client) DeleteProxyRoute(ctx context.Context, routeID string) error {
+	err := c.retryOnConflict(ctx, func() error {
+		etag, err := c.doIfMatch(ctx, http.MethodGet, idPath(routeID), "", nil, nil)
+		if err != nil {
+			return err
+		}
+		_, err = c.doIfMatch(ctx, http.MethodDelete, idPath(routeID), etag, nil, nil)
+		return err
+	})
+	if IsNotFound(err) {
+		// Route already doesn't exist
+		return nil
 	}
-
-	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to get routes: %w", err)
+		return fmt.Errorf("failed to delete route: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return nil
+}
 
-	if resp.StatusCode == http.StatusNotFound {
-		// Route already doesn't exist
-		return nil
+// GetProxyRoute retrieves the proxy route with the supplied @id from Caddy.
+func (c *Client) GetProxyRoute(ctx context.Context, routeID string) (*ProxyRoute, error) {
+	route := &ProxyRoute{}
+	if err := c.do(ctx, http.MethodGet, idPath(routeID), nil, route); err != nil {
+		return nil, fmt.Errorf("failed to get route: %w", err)
 	}
+	return route, nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("caddy API returned status %d: %s", resp.StatusCode, string(body))
+// ListProxyRoutes retrieves all routes of the supplied server, in the order
+// Caddy evaluates them. This includes routes not created by this client, so
+// fields that can't be decoded are left empty rather than returning an error.
+func (c *Client) ListProxyRoutes(ctx context.Context, serverName string) ([]ProxyRoute, error) {
+	_, routes, _, err := c.readRoutes(ctx, serverName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %w", serverError(serverName, err))
 	}
+	return routes, nil
+}
 
-	var routes []ProxyRoute
-	if err := json.NewDecoder(resp.Body).Decode(&routes); err != nil {
-		return fmt.Errorf("failed to decode routes: %w", err)
+// readRoutes returns the routes array of the supplied server both as raw JSON,
+// which preserves routes exactly, and decoded, along with its Etag.
+func (c *Client) readRoutes(ctx context.Context, serverName string) ([]json.RawMessage, []ProxyRoute, string, error) {
+	var raw []json.RawMessage
+	etag, err := c.doIfMatch(ctx, http.MethodGet, routesPath(serverName), "", nil, &raw)
+	if err != nil {
+		return nil, nil, "", err
 	}
+	routes := make([]ProxyRoute, len(raw))
+	for i := range raw {
+		// Unmarshal decodes as much as it can when a value doesn't fit the
+		// type of its field, e.g. a duration configured as an integer.
+		_ = json.Unmarshal(raw[i], &routes[i])
+	}
+	return raw, routes, etag, nil
+}
 
-	// Find and remove the route with matching ID
-	index := -1
-	for i, r := range routes {
-		if generateRouteID(&r) == routeID {
-			index = i
-			break
+// AdoptLegacyRoute finds the route identified by legacyID, an external name in
+// the match-derived format used before routes were tagged with an @id, and
+// tags it with routeID. It returns routeID if the route is now tagged with it,
+// or an empty string if no route matches legacyID. Only reverse proxy routes
+// that are untagged or already tagged with routeID are adopted, so a route
+// another ProxyRoute manages, or one this client didn't create, is never taken
+// over. Routes are never adopted by the legacy ID of a route without matchers,
+// which can't be told apart from other catch-all routes.
+func (c *Client) AdoptLegacyRoute(ctx context.Context, serverName, legacyID, routeID string) (string, error) {
+	if legacyID == legacyDefaultRouteID {
+		return "", nil
+	}
+
+	var id string
+	err := c.retryOnConflict(ctx, func() error {
+		id = ""
+
+		// The route is addressed by its index, which is only valid as long
+		// as the routes array is unchanged.
+		_, routes, etag, err := c.readRoutes(ctx, serverName)
+		if err != nil {
+			return err
 		}
-	}
 
-	if index == -1 {
-		// Route not found, consider it already deleted
+		for i := range routes {
+			r := &routes[i]
+			if (r.ID != "" && r.ID != routeID) || !isReverseProxy(r) || legacyRouteID(r) != legacyID {
+				continue
+			}
+			if r.ID == routeID {
+				id = routeID
+				return nil
+			}
+			path := fmt.Sprintf("%s/%d/@id", routesPath(serverName), i)
+			if _, err := c.doIfMatch(ctx, http.MethodPut, path, etag, routeID, nil); err != nil {
+				return fmt.Errorf("failed to tag route with @id: %w", err)
+			}
+			id = routeID
+			return nil
+		}
 		return nil
+	})
+	err = serverError(serverName, err)
+	if IsServerNotFound(err) {
+		// The route can't exist if its server doesn't.
+		return "", nil
 	}
-
-	// Delete the route at the found index
-	deletePath := fmt.Sprintf("/config/apps/http/servers/%s/routes/%d", serverName, index)
-	req, err = http.NewRequestWithContext(ctx, http.MethodDelete, c.endpoint+deletePath, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create delete request: %w", err)
+		return "", fmt.Errorf("failed to adopt route: %w", err)
 	}
+	return id, nil
+}
 
-	resp, err = c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to delete route: %w", err)
+// isReverseProxy reports whether the supplied route is handled by a reverse
+// proxy, as every route created by this client is.
+func isReverseProxy(r *ProxyRoute) bool {
+	for i := range r.Handle {
+		if r.Handle[i].Handler == "reverse_proxy" {
+			return true
+		}
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return false
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("caddy API returned status %d: %s", resp.StatusCode, string(body))
+// GetUpstreamStatus retrieves the health status of upstreams.
+func (c *Client) GetUpstreamStatus(ctx context.Context) ([]UpstreamStatus, error) {
+	var upstreams []UpstreamStatus
+	if err := c.do(ctx, http.MethodGet, "/reverse_proxy/upstreams", nil, &upstreams); err != nil {
+		return nil, fmt.Errorf("failed to get upstream status: %w", err)
 	}
+	return upstreams, nil
+}
 
-	return nil
+// do sends a request to the Caddy admin API. A non-nil in is encoded as the
+// JSON request body, and a successful response body is decoded into a non-nil
+// out. Responses outside the 2xx range are returned as an *APIError, and
+// failures to reach the admin API as ErrUnavailable.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	_, err := c.doIfMatch(ctx, method, path, "", in, out)
+	return err
 }
 
-// GetProxyRoute retrieves a proxy route from Caddy.
-func (c *Client) GetProxyRoute(ctx context.Context, serverName, routeID string) (*ProxyRoute, error) {
-	path := fmt.Sprintf("/config/apps/http/servers/%s/routes", serverName)
+// doIfMatch is like do, but sends a non-empty ifMatch as the If-Match header,
+// which makes Caddy reject the request with 412 Precondition Failed unless the
+// config ifMatch was read from is unchanged. It returns the response's Etag,
+// which Caddy sets when reading config.
+func (c *Client) doIfMatch(ctx context.Context, method, path, ifMatch string, in, out any) (string, error) {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = b
+	}
+
+	// A request guarded by If-Match can't be applied twice: if a response
+	// is lost after Caddy applied it, the retry fails with 412 Precondition
+	// Failed rather than repeating the change.
+	retryable := ifMatch != "" || idempotent(method)
+
+	for attempt := 0; ; attempt++ {
+		etag, err := c.send(ctx, method, path, ifMatch, body, out)
+		if err == nil || !retryable || !IsUnavailable(err) || attempt >= c.requests.MaxRetries {
+			return etag, err
+		}
+
+		t := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return "", err
+		case <-t.C:
+		}
+	}
+}
+
+// send makes a single attempt at a request. See doIfMatch.
+func (c *Client) send(ctx context.Context, method, path, ifMatch string, body []byte, out any) (string, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, nil)
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	switch {
+	case c.credentials.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.credentials.Token)
+	case c.credentials.Username != "":
+		req.SetBasicAuth(c.credentials.Username, c.credentials.Password)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get routes: %w", err)
+		return "", fmt.Errorf("%w: %w", ErrUnavailable, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("route not found")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return "", newAPIError(resp.StatusCode, b)
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("caddy API returned status %d: %s", resp.StatusCode, string(body))
+	etag := resp.Header.Get("Etag")
+	if out == nil {
+		return etag, nil
 	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return etag, nil
+}
 
-	var routes []ProxyRoute
-	if err := json.NewDecoder(resp.Body).Decode(&routes); err != nil {
-		return nil, fmt.Errorf("failed to decode routes: %w", err)
+// backoff returns how long to wait before retrying a request that failed for
+// the supplied, zero-indexed, attempt. The delay doubles with each attempt up
+// to maxRetryBackoff, and is jittered so that reconcilers that failed together
+// don't retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	if c.requests.RetryBackoff == 0 {
+		return 0
+	}
+	d := c.requests.RetryBackoff << attempt
+	if d>>attempt != c.requests.RetryBackoff || d > maxRetryBackoff {
+		// The delay overflowed, or exceeds the cap.
+		d = maxRetryBackoff
 	}
+	return d/2 + rand.N(d/2+1) //nolint:gosec // Jitter needn't be cryptographically random.
+}
 
-	// Find the route with matching ID
-	for _, r := range routes {
-		if generateRouteID(&r) == routeID {
-			return &r, nil
+// idempotent reports whether a request with the supplied method may safely be
+// repeated. Caddy's PUT inserts into arrays and POST appends to them, so
+// neither is idempotent.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryOnConflict calls fn, a read-modify-write cycle that passes the Etag it
+// read as If-Match, until it does not fail with 412 Precondition Failed or
+// maxConflictRetries is exhausted.
+func (c *Client) retryOnConflict(ctx context.Context, fn func() error) error {
+	var err error
+	for range maxConflictRetries {
+		if err = fn(); !hasStatus(err, http.StatusPreconditionFailed) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 	}
+	return err
+}
 
-	return nil, fmt.Errorf("route not found")
+// unixSocketPath returns the socket path of an endpoint in Caddy's Unix
+// network address syntax, e.g. "/run/caddy/admin.sock" for
+// "unix//run/caddy/admin.sock". Caddy's optional "|<mode>" file mode suffix is
+// ignored.
+func unixSocketPath(endpoint string) (string, bool) {
+	path, ok := strings.CutPrefix(endpoint, "unix/")
+	if !ok {
+		return "", false
+	}
+	path, _, _ = strings.Cut(path, "|")
+	return path, true
 }
 
-// GetUpstreamStatus retrieves the health status of upstreams.
-func (c *Client) GetUpstreamStatus(ctx context.Context) ([]UpstreamStatus, error) {
-	path := "/reverse_proxy/upstreams"
+// routesPath returns the config path of the routes array of a server.
+func routesPath(serverName string) string {
+	return fmt.Sprintf("/config/apps/http/servers/%s/routes", url.PathEscape(serverName))
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+// idPath returns the path that addresses the config object with the supplied
+// @id.
+func idPath(id string) string {
+	return "/id/" + url.PathEscape(id)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get upstream status: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
+// legacyDefaultRouteID is the legacy ID of a route without matchers.
+const legacyDefaultRouteID = "default"
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("caddy API returned status %d: %s", resp.StatusCode, string(body))
+// IsLegacyRouteID reports whether id is in the match-derived format (e.g.
+// "host:example.com|path:/api/*") that identified routes before they were
+// tagged with an @id.
+func IsLegacyRouteID(id string) bool {
+	if id == legacyDefaultRouteID {
+		return true
 	}
-
-	var upstreams []UpstreamStatus
-	if err := json.NewDecoder(resp.Body).Decode(&upstreams); err != nil {
-		return nil, fmt.Errorf("failed to decode upstreams: %w", err)
+	for _, part := range strings.Split(id, "|") {
+		if !strings.HasPrefix(part, "host:") && !strings.HasPrefix(part, "path:") && !strings.HasPrefix(part, "method:") {
+			return false
+		}
 	}
-
-	return upstreams, nil
+	return true
 }
 
-// generateRouteID generates a unique ID for a route based on its configuration.
-// This is a simplified implementation - in production, you'd use Caddy's @id directive.
-func generateRouteID(route *ProxyRoute) string {
+// legacyRouteID derives the legacy, match-derived ID of a route. It exists only
+// to find routes created before routes were tagged with an @id.
+func legacyRouteID(route *ProxyRoute) string {
 	if len(route.Match) == 0 {
-		return "default"
+		return legacyDefaultRouteID
 	}
 
 	// Use first matcher set for ID generation
@@ -347,7 +930,7 @@ func generateRouteID(route *ProxyRoute) string {
 	}
 
 	if len(parts) == 0 {
-		return "default"
+		return legacyDefaultRouteID
 	}
 
 	return strings.Join(parts, "|")
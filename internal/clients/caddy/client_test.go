@@ -0,0 +1,1085 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package caddy_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+	"github.com/crossplane/provider-caddy/internal/clients/caddy/fake"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+const (
+	routeA = "proxyroute-a"
+	routeB = "proxyroute-b"
+)
+
+// noRetries makes requests fail fast, so tests don't wait on backoff.
+var noRetries = caddyclient.WithRequestOptions(caddyclient.RequestOptions{})
+
+func route(dial string, host ...string) *caddyclient.ProxyRoute {
+	r := &caddyclient.ProxyRoute{
+		Handle:   []caddyclient.Handler{{Handler: "reverse_proxy", Upstreams: []caddyclient.Upstream{{Dial: dial}}}},
+		Terminal: true,
+	}
+	if len(host) > 0 {
+		r.Match = []caddyclient.MatchSet{{Host: host}}
+	}
+	return r
+}
+
+func tagged(id string, r *caddyclient.ProxyRoute) caddyclient.ProxyRoute {
+	out := *r
+	out.ID = id
+	return out
+}
+
+// byDial orders routes by the dial address of their first upstream.
+func byDial(a, b *caddyclient.ProxyRoute) bool {
+	return a.Handle[0].Upstreams[0].Dial < b.Handle[0].Upstreams[0].Dial
+}
+
+func newClient(t *testing.T, endpoint string, opts ...caddyclient.Option) *caddyclient.Client {
+	t.Helper()
+	c, err := caddyclient.NewClient(endpoint, append([]caddyclient.Option{noRetries}, opts...)...)
+	if err != nil {
+		t.Fatalf("caddyclient.NewClient(...): %v", err)
+	}
+	t.Cleanup(c.CloseIdleConnections)
+	return c
+}
+
+func TestCreateProxyRoute(t *testing.T) {
+	type args struct {
+		serverName string
+		routeID    string
+		route      *caddyclient.ProxyRoute
+		order      caddyclient.Order
+	}
+	type want struct {
+		routes []caddyclient.ProxyRoute
+		check  func(error) bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		config string
+		faults []fake.Fault
+		args   args
+		want   want
+	}{
+		"Append": {
+			reason: "A route should be appended when there is no order.",
+			config: `{"apps":{"http":{"servers":{"srv0":{"routes":[{"@id":"proxyroute-b","handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"b:80"}]}],"terminal":true}]}}}}}`,
+			args:   args{serverName: "srv0", routeID: routeA, route: route("a:80")},
+			want: want{
+				routes: []caddyclient.ProxyRoute{tagged(routeB, route("b:80")), tagged(routeA, route("a:80"))},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"InsertInOrder": {
+			reason: "A route should be inserted before the first route it should be evaluated before.",
+			config: `{"apps":{"http":{"servers":{"srv0":{"routes":[{"@id":"proxyroute-b","handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"b:80"}]}],"terminal":true}]}}}}}`,
+			args:   args{serverName: "srv0", routeID: routeA, route: route("a:80"), order: byDial},
+			want: want{
+				routes: []caddyclient.ProxyRoute{tagged(routeA, route("a:80")), tagged(routeB, route("b:80"))},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"ResponseLost": {
+			reason: "A route whose creation succeeded but whose response was lost should not be created twice when the request is retried.",
+			faults: []fake.Fault{{Method: http.MethodPost, Drop: true, Apply: true}},
+			args:   args{serverName: "srv0", routeID: routeA, route: route("a:80")},
+			want: want{
+				routes: []caddyclient.ProxyRoute{tagged(routeA, route("a:80"))},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"ServerNotFound": {
+			reason: "Creating a route in a server that doesn't exist should return ErrServerNotFound.",
+			args:   args{serverName: "srv1", routeID: routeA, route: route("a:80")},
+			want:   want{check: caddyclient.IsServerNotFound},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []fake.Option{}
+			if tc.config != "" {
+				opts = append(opts, fake.WithConfig(tc.config))
+			}
+			srv := fake.NewServer(opts...)
+			defer srv.Close()
+			srv.Inject(tc.faults...)
+
+			c := newClient(t, srv.URL, caddyclient.WithRequestOptions(caddyclient.RequestOptions{MaxRetries: 1}))
+			err := c.CreateProxyRoute(context.Background(), tc.args.serverName, tc.args.routeID, tc.args.route, tc.args.order)
+			if !tc.want.check(err) {
+				t.Errorf("\n%s\nc.CreateProxyRoute(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.routes, srv.Routes(tc.args.serverName)); diff != "" {
+				t.Errorf("\n%s\nc.CreateProxyRoute(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateProxyRoute(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		routeID string
+		want    []caddyclient.ProxyRoute
+		check   func(error) bool
+	}{
+		"UpdateInPlace": {
+			reason:  "A route should be replaced in place, keeping its position.",
+			routeID: routeA,
+			want:    []caddyclient.ProxyRoute{tagged(routeA, route("c:80")), tagged(routeB, route("b:80"))},
+			check:   func(err error) bool { return err == nil },
+		},
+		"NotFound": {
+			reason:  "Updating a route that doesn't exist should return ErrNotFound.",
+			routeID: "proxyroute-missing",
+			want:    []caddyclient.ProxyRoute{tagged(routeA, route("a:80")), tagged(routeB, route("b:80"))},
+			check:   caddyclient.IsNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			c := newClient(t, srv.URL)
+			for _, r := range []caddyclient.ProxyRoute{tagged(routeA, route("a:80")), tagged(routeB, route("b:80"))} {
+				if err := c.CreateProxyRoute(context.Background(), "srv0", r.ID, &r, nil); err != nil {
+					t.Fatalf("c.CreateProxyRoute(...): %v", err)
+				}
+			}
+
+			err := c.UpdateProxyRoute(context.Background(), tc.routeID, route("c:80"))
+			if !tc.check(err) {
+				t.Errorf("\n%s\nc.UpdateProxyRoute(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Routes("srv0")); diff != "" {
+				t.Errorf("\n%s\nc.UpdateProxyRoute(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteProxyRoute(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		routeID string
+		want    []caddyclient.ProxyRoute
+	}{
+		"Delete": {
+			reason:  "Only the route with the supplied @id should be deleted.",
+			routeID: routeA,
+			want:    []caddyclient.ProxyRoute{tagged(routeB, route("b:80"))},
+		},
+		"NotFound": {
+			reason:  "Deleting a route that doesn't exist should not be an error.",
+			routeID: "proxyroute-missing",
+			want:    []caddyclient.ProxyRoute{tagged(routeA, route("a:80")), tagged(routeB, route("b:80"))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			c := newClient(t, srv.URL)
+			for _, r := range []caddyclient.ProxyRoute{tagged(routeA, route("a:80")), tagged(routeB, route("b:80"))} {
+				if err := c.CreateProxyRoute(context.Background(), "srv0", r.ID, &r, nil); err != nil {
+					t.Fatalf("c.CreateProxyRoute(...): %v", err)
+				}
+			}
+
+			if err := c.DeleteProxyRoute(context.Background(), tc.routeID); err != nil {
+				t.Errorf("\n%s\nc.DeleteProxyRoute(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Routes("srv0")); diff != "" {
+				t.Errorf("\n%s\nc.DeleteProxyRoute(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestIfMatch changes the config before each of the supplied number of writes
+// guarded by If-Match, so that they fail with 412 Precondition Failed.
+func TestIfMatch(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		conflicts int32
+		want      []caddyclient.ProxyRoute
+		check     func(error) bool
+	}{
+		"RetryOnConflict": {
+			reason:    "A write that fails with 412 Precondition Failed should be retried with the config read again.",
+			conflicts: 2,
+			want:      []caddyclient.ProxyRoute{tagged(routeA, route("c:80"))},
+			check:     func(err error) bool { return err == nil },
+		},
+		"ConflictRetriesExhausted": {
+			reason:    "A write that keeps failing with 412 Precondition Failed should return ErrConflict.",
+			conflicts: 100,
+			// The route as last changed by the other writer, once for each
+			// of the client's five attempts.
+			want: []caddyclient.ProxyRoute{tagged(routeA, &caddyclient.ProxyRoute{
+				Handle:   []caddyclient.Handler{{Handler: "reverse_proxy", Upstreams: []caddyclient.Upstream{{Dial: "a:80", MaxRequests: 5}}}},
+				Terminal: true,
+			})},
+			check: caddyclient.IsConflict,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			if err := newClient(t, srv.URL).CreateProxyRoute(context.Background(), "srv0", routeA, route("a:80"), nil); err != nil {
+				t.Fatalf("c.CreateProxyRoute(...): %v", err)
+			}
+
+			// Another writer changes the route between the client reading
+			// and writing it. Etags are scoped to the path they were read
+			// from, so only a change to the route itself conflicts.
+			conflicts, writes := tc.conflicts, int32(0)
+			front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-Match") != "" && atomic.AddInt32(&conflicts, -1) >= 0 {
+					body := strconv.Itoa(int(atomic.AddInt32(&writes, 1)))
+					req := httptest.NewRequest(http.MethodPost, "/id/"+routeA+"/handle/0/upstreams/0/max_requests", bytes.NewBufferString(body))
+					req.Header.Set("Content-Type", "application/json")
+					srv.Config.Handler.ServeHTTP(httptest.NewRecorder(), req)
+				}
+				srv.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer front.Close()
+
+			err := newClient(t, front.URL).UpdateProxyRoute(context.Background(), routeA, route("c:80"))
+			if !tc.check(err) {
+				t.Errorf("\n%s\nc.UpdateProxyRoute(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Routes("srv0")); diff != "" {
+				t.Errorf("\n%s\nc.UpdateProxyRoute(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	const routes = "/config/apps/http/servers/srv0/routes"
+
+	type args struct {
+		method  string
+		path    string
+		ifMatch bool
+		in      any
+		opts    caddyclient.RequestOptions
+		faults  []fake.Fault
+	}
+	type want struct {
+		check    func(error) bool
+		attempts int
+		routes   int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetryGet": {
+			reason: "A GET that failed with a server error should be retried.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{StatusCode: http.StatusServiceUnavailable}},
+			},
+			want: want{check: func(err error) bool { return err == nil }, attempts: 2},
+		},
+		"RetryDroppedConnection": {
+			reason: "A GET whose connection was dropped should be retried.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{Drop: true}},
+			},
+			want: want{check: func(err error) bool { return err == nil }, attempts: 2},
+		},
+		"RetryPatch": {
+			reason: "A PATCH, which replaces a value, should be retried.",
+			args: args{
+				method: http.MethodPatch, path: routes, in: []caddyclient.ProxyRoute{},
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{StatusCode: http.StatusBadGateway}},
+			},
+			want: want{check: func(err error) bool { return err == nil }, attempts: 2},
+		},
+		"NoRetryPost": {
+			reason: "A POST, which appends to an array, should not be retried unless it is guarded by If-Match.",
+			args: args{
+				method: http.MethodPost, path: routes, in: route("a:80"),
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{StatusCode: http.StatusServiceUnavailable}},
+			},
+			want: want{check: caddyclient.IsUnavailable, attempts: 1},
+		},
+		"NoRetryPostResponseLost": {
+			reason: "A POST whose response was lost should not be retried unless it is guarded by If-Match, as it may have been applied.",
+			args: args{
+				method: http.MethodPost, path: routes, in: route("a:80"),
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{Drop: true, Apply: true}},
+			},
+			want: want{check: caddyclient.IsUnavailable, attempts: 1, routes: 1},
+		},
+		"NoRetryPut": {
+			reason: "A PUT, which inserts into an array, should not be retried unless it is guarded by If-Match.",
+			args: args{
+				method: http.MethodPut, path: routes + "/0", in: route("a:80"),
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{StatusCode: http.StatusServiceUnavailable}},
+			},
+			want: want{check: caddyclient.IsUnavailable, attempts: 1},
+		},
+		"RetryGuardedPost": {
+			reason: "A POST guarded by If-Match should be retried.",
+			args: args{
+				method: http.MethodPost, path: routes, ifMatch: true, in: route("a:80"),
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{Method: http.MethodPost, StatusCode: http.StatusServiceUnavailable}},
+			},
+			want: want{check: func(err error) bool { return err == nil }, attempts: 2, routes: 1},
+		},
+		"GuardedPostResponseLost": {
+			reason: "A retried POST guarded by If-Match should fail rather than be applied twice if its response was lost.",
+			args: args{
+				method: http.MethodPost, path: routes, ifMatch: true, in: route("a:80"),
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{Method: http.MethodPost, Drop: true, Apply: true}},
+			},
+			want: want{check: caddyclient.IsConflict, attempts: 2, routes: 1},
+		},
+		"NoRetryClientError": {
+			reason: "A request Caddy rejected should not be retried.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts:   caddyclient.RequestOptions{MaxRetries: 1},
+				faults: []fake.Fault{{StatusCode: http.StatusBadRequest}},
+			},
+			want: want{check: caddyclient.IsInvalidConfig, attempts: 1},
+		},
+		"ExhaustRetries": {
+			reason: "A request should fail once it has been retried MaxRetries times.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts: caddyclient.RequestOptions{MaxRetries: 2},
+				faults: []fake.Fault{
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusServiceUnavailable},
+					{StatusCode: http.StatusServiceUnavailable},
+				},
+			},
+			want: want{check: caddyclient.IsUnavailable, attempts: 3},
+		},
+		"Timeout": {
+			reason: "A request should fail once its timeout has passed.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts:   caddyclient.RequestOptions{Timeout: 50 * time.Millisecond},
+				faults: []fake.Fault{{Delay: time.Minute}},
+			},
+			want: want{check: caddyclient.IsUnavailable, attempts: 1},
+		},
+		"RetryTimeout": {
+			reason: "The timeout should apply to each attempt at a request, not to all of them.",
+			args: args{
+				method: http.MethodGet, path: "/config/",
+				opts:   caddyclient.RequestOptions{Timeout: 50 * time.Millisecond, MaxRetries: 1},
+				faults: []fake.Fault{{Delay: time.Minute}},
+			},
+			want: want{check: func(err error) bool { return err == nil }, attempts: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+
+			c := newClient(t, srv.URL, caddyclient.WithRequestOptions(tc.args.opts))
+			ifMatch := ""
+			if tc.args.ifMatch {
+				etag, err := c.DoIfMatch(context.Background(), http.MethodGet, routes, "", nil, nil)
+				if err != nil {
+					t.Fatalf("c.DoIfMatch(...): %v", err)
+				}
+				ifMatch = etag
+			}
+			before := len(srv.Requests())
+			srv.Inject(tc.args.faults...)
+
+			_, err := c.DoIfMatch(context.Background(), tc.args.method, tc.args.path, ifMatch, tc.args.in, nil)
+			if !tc.want.check(err) {
+				t.Errorf("\n%s\nc.DoIfMatch(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.attempts, len(srv.Requests())-before); diff != "" {
+				t.Errorf("\n%s\nc.DoIfMatch(...): -want attempts, +got attempts:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.routes, len(srv.Routes("srv0"))); diff != "" {
+				t.Errorf("\n%s\nc.DoIfMatch(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	type args struct {
+		backoff time.Duration
+		attempt int
+	}
+	type want struct {
+		min time.Duration
+		max time.Duration
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoBackoff": {
+			reason: "A zero backoff should retry immediately.",
+			args:   args{attempt: 2},
+		},
+		"FirstRetry": {
+			reason: "The first retry should wait for up to the backoff, and at least half of it.",
+			args:   args{backoff: 100 * time.Millisecond},
+			want:   want{min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		},
+		"Doubled": {
+			reason: "The backoff should double with each retry.",
+			args:   args{backoff: 100 * time.Millisecond, attempt: 2},
+			want:   want{min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		},
+		"Capped": {
+			reason: "The backoff should not exceed 10 seconds.",
+			args:   args{backoff: time.Second, attempt: 10},
+			want:   want{min: 5 * time.Second, max: 10 * time.Second},
+		},
+		"Overflow": {
+			reason: "A backoff that overflows when doubled should be capped.",
+			args:   args{backoff: time.Second, attempt: 40},
+			want:   want{min: 5 * time.Second, max: 10 * time.Second},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newClient(t, "http://caddy:2019", caddyclient.WithRequestOptions(caddyclient.RequestOptions{RetryBackoff: tc.args.backoff}))
+			for range 100 {
+				if got := c.Backoff(tc.args.attempt); got < tc.want.min || got > tc.want.max {
+					t.Fatalf("\n%s\nc.Backoff(%d): want between %s and %s, got %s", tc.reason, tc.args.attempt, tc.want.min, tc.want.max, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	type want struct {
+		d   time.Duration
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		s      string
+		want   want
+	}{
+		"GoDuration": {
+			reason: "A Go duration should parse as it does in Go.",
+			s:      "1h30m",
+			want:   want{d: 90 * time.Minute},
+		},
+		"Days": {
+			reason: "A day should be 24 hours.",
+			s:      "1d",
+			want:   want{d: 24 * time.Hour},
+		},
+		"FractionalDays": {
+			reason: "A fractional number of days should be converted to hours.",
+			s:      "1.5d",
+			want:   want{d: 36 * time.Hour},
+		},
+		"DaysAndOtherUnits": {
+			reason: "Days should combine with other units, wherever they appear.",
+			s:      "1h2d30m",
+			want:   want{d: 49*time.Hour + 30*time.Minute},
+		},
+		"NegativeDays": {
+			reason: "A negative sign should apply to the whole duration.",
+			s:      "-1d12h",
+			want:   want{d: -36 * time.Hour},
+		},
+		"Microseconds": {
+			reason: "Units that aren't ASCII should parse.",
+			s:      "10µs",
+			want:   want{d: 10 * time.Microsecond},
+		},
+		"DaysWithoutNumber": {
+			reason: "A day unit without a number should be an error.",
+			s:      "d",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"UnknownUnit": {
+			reason: "A unit neither Go nor Caddy knows should be an error.",
+			s:      "1w",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"NoUnit": {
+			reason: "A number without a unit should be an error.",
+			s:      "30",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"TooLong": {
+			reason: "A duration longer than 1024 characters should be an error.",
+			s:      strings.Repeat("1", 1024) + "s",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := caddyclient.ParseDuration(tc.s)
+			got := want{d: d, err: err}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseDuration(%q): -want, +got:\n%s\n", tc.reason, tc.s, diff)
+			}
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		opts   []caddyclient.Option
+		check  func(error) bool
+	}{
+		"NoCredentials": {
+			reason: "A request without credentials should return ErrUnauthorized.",
+			check:  caddyclient.IsUnauthorized,
+		},
+		"WrongCredentials": {
+			reason: "A request with the wrong credentials should return ErrUnauthorized.",
+			opts:   []caddyclient.Option{caddyclient.WithCredentials(caddyclient.Credentials{Token: "wrong"})},
+			check:  caddyclient.IsUnauthorized,
+		},
+		"Credentials": {
+			reason: "A request with the right credentials should succeed.",
+			opts:   []caddyclient.Option{caddyclient.WithCredentials(caddyclient.Credentials{Token: "secret"})},
+			check:  func(err error) bool { return err == nil },
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(fake.WithBearerToken("secret"))
+			defer srv.Close()
+
+			_, err := newClient(t, srv.URL, tc.opts...).ListProxyRoutes(context.Background(), "srv0")
+			if !tc.check(err) {
+				t.Errorf("\n%s\nc.ListProxyRoutes(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	srv := fake.NewServer(fake.WithUnixSocket(socket))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		reason   string
+		endpoint string
+		check    func(error) bool
+	}{
+		"UnixSocket": {
+			reason:   "A client should reach an admin API listening on a Unix socket, with a Host header Caddy accepts.",
+			endpoint: "unix/" + socket,
+			check:    func(err error) bool { return err == nil },
+		},
+		"FileMode": {
+			reason:   "A client should ignore the file mode suffix of a Unix socket address.",
+			endpoint: "unix/" + socket + "|0220",
+			check:    func(err error) bool { return err == nil },
+		},
+		"NoSocket": {
+			reason:   "A client should report a Unix socket that doesn't exist as unavailable.",
+			endpoint: "unix/" + filepath.Join(t.TempDir(), "missing.sock"),
+			check:    caddyclient.IsUnavailable,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newClient(t, tc.endpoint).ListProxyRoutes(context.Background(), "srv0")
+			if !tc.check(err) {
+				t.Errorf("\n%s\nc.ListProxyRoutes(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+// newCertificate returns a certificate made from the supplied template, and
+// its key, both PEM encoded. The certificate is signed by the supplied parent
+// and its key, or self-signed if they're nil.
+func newCertificate(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey(...): %v", err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate(...): %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate(...): %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("x509.MarshalECPrivateKey(...): %v", err)
+	}
+	return cert, key,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLS(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour)
+
+	// clientCA issues the client certificate, and an admin API that requires
+	// client certificates trusts only it.
+	clientCA, clientCAKey, _, _ := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	_, _, clientCert, clientKey := newCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "provider-caddy"},
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, clientCA, clientCAKey)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+
+	// otherCA did not issue the admin API's certificate.
+	_, _, otherCA, _ := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+
+	// serverCA returns the PEM encoded CA that issued the admin API's
+	// certificate, which is valid for example.com and the loopback
+	// addresses.
+	serverCA := func(srv *fake.Server) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	}
+
+	type args struct {
+		server *tls.Config
+		tls    func(srv *fake.Server) caddyclient.TLSOptions
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		check  func(error) bool
+	}{
+		"PinnedCA": {
+			reason: "An admin API whose certificate is issued by the pinned CA should be trusted.",
+			args: args{
+				server: &tls.Config{},
+				tls:    func(srv *fake.Server) caddyclient.TLSOptions { return caddyclient.TLSOptions{CAPEM: serverCA(srv)} },
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		"UntrustedCA": {
+			reason: "An admin API whose certificate is issued by a CA that isn't trusted should be unavailable.",
+			args: args{
+				server: &tls.Config{},
+				tls:    func(_ *fake.Server) caddyclient.TLSOptions { return caddyclient.TLSOptions{} },
+			},
+			check: caddyclient.IsUnavailable,
+		},
+		"OtherCA": {
+			reason: "An admin API whose certificate is not issued by the pinned CA should be unavailable.",
+			args: args{
+				server: &tls.Config{},
+				tls:    func(_ *fake.Server) caddyclient.TLSOptions { return caddyclient.TLSOptions{CAPEM: otherCA} },
+			},
+			check: caddyclient.IsUnavailable,
+		},
+		"InsecureSkipVerify": {
+			reason: "An admin API's certificate should not be verified when verification is disabled.",
+			args: args{
+				server: &tls.Config{},
+				tls:    func(_ *fake.Server) caddyclient.TLSOptions { return caddyclient.TLSOptions{InsecureSkipVerify: true} },
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		"ServerName": {
+			reason: "An admin API's certificate should be verified against the overridden server name.",
+			args: args{
+				server: &tls.Config{},
+				tls: func(srv *fake.Server) caddyclient.TLSOptions {
+					return caddyclient.TLSOptions{CAPEM: serverCA(srv), ServerName: "example.com"}
+				},
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		"WrongServerName": {
+			reason: "An admin API whose certificate is not valid for the overridden server name should be unavailable.",
+			args: args{
+				server: &tls.Config{},
+				tls: func(srv *fake.Server) caddyclient.TLSOptions {
+					return caddyclient.TLSOptions{CAPEM: serverCA(srv), ServerName: "caddy.example.org"}
+				},
+			},
+			check: caddyclient.IsUnavailable,
+		},
+		"ClientCertificate": {
+			reason: "An admin API that requires a client certificate should accept one issued by a CA it trusts.",
+			args: args{
+				server: &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs},
+				tls: func(srv *fake.Server) caddyclient.TLSOptions {
+					return caddyclient.TLSOptions{CAPEM: serverCA(srv), CertPEM: clientCert, KeyPEM: clientKey}
+				},
+			},
+			check: func(err error) bool { return err == nil },
+		},
+		"NoClientCertificate": {
+			reason: "An admin API that requires a client certificate should be unavailable without one.",
+			args: args{
+				server: &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs},
+				tls:    func(srv *fake.Server) caddyclient.TLSOptions { return caddyclient.TLSOptions{CAPEM: serverCA(srv)} },
+			},
+			check: caddyclient.IsUnavailable,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(fake.WithTLS(tc.args.server))
+			defer srv.Close()
+
+			_, err := newClient(t, srv.URL, caddyclient.WithTLS(tc.args.tls(srv))).ListProxyRoutes(context.Background(), "srv0")
+			if !tc.check(err) {
+				t.Errorf("\n%s\nc.ListProxyRoutes(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	_, _, cert, key := newCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "provider-caddy"},
+		NotAfter:     time.Now().Add(time.Hour),
+	}, nil, nil)
+
+	cases := map[string]struct {
+		reason string
+		o      caddyclient.TLSOptions
+		check  func(error) bool
+	}{
+		"Valid": {
+			reason: "Valid TLS material should be accepted.",
+			o:      caddyclient.TLSOptions{CAPEM: cert, CertPEM: cert, KeyPEM: key},
+			check:  func(err error) bool { return err == nil },
+		},
+		"InvalidCA": {
+			reason: "A CA bundle without PEM encoded certificates should return ErrInvalidTLSConfig.",
+			o:      caddyclient.TLSOptions{CAPEM: []byte("not a certificate")},
+			check:  caddyclient.IsInvalidTLSConfig,
+		},
+		"InvalidClientCertificate": {
+			reason: "A client certificate that can't be parsed should return ErrInvalidTLSConfig.",
+			o:      caddyclient.TLSOptions{CertPEM: []byte("not a certificate"), KeyPEM: key},
+			check:  caddyclient.IsInvalidTLSConfig,
+		},
+		"MissingKey": {
+			reason: "A client certificate without a key should return ErrInvalidTLSConfig.",
+			o:      caddyclient.TLSOptions{CertPEM: cert},
+			check:  caddyclient.IsInvalidTLSConfig,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := caddyclient.NewClient("https://caddy:2019", caddyclient.WithTLS(tc.o))
+			if !tc.check(err) {
+				t.Errorf("\n%s\ncaddyclient.NewClient(...): unexpected error: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestAdoptLegacyRoute(t *testing.T) {
+	// legacy is a route created before routes were tagged with an @id. Its
+	// durations are integers, as when adapted from a Caddyfile.
+	legacy := `{"match":[{"host":["example.com"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"a:80"}],"health_checks":{"active":{"interval":30000000000}}}],"terminal":true}`
+
+	type args struct {
+		serverName string
+		legacyID   string
+	}
+	type want struct {
+		id     string
+		routes []string
+		check  func(error) bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		routes []string
+		args   args
+		want   want
+	}{
+		"Adopt": {
+			reason: "An untagged reverse proxy route matching the legacy ID should be tagged with the route ID.",
+			routes: []string{`{"handle":[{"handler":"file_server"}]}`, legacy},
+			args:   args{serverName: "srv0", legacyID: "host:example.com"},
+			want: want{
+				id:     routeA,
+				routes: []string{"", routeA},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"AlreadyAdopted": {
+			reason: "A route already tagged with the route ID should be returned as is.",
+			routes: []string{`{"@id":"proxyroute-a","match":[{"host":["example.com"]}],"handle":[{"handler":"reverse_proxy"}]}`},
+			args:   args{serverName: "srv0", legacyID: "host:example.com"},
+			want: want{
+				id:     routeA,
+				routes: []string{routeA},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"OwnedByAnother": {
+			reason: "A route tagged with another @id should not be adopted.",
+			routes: []string{`{"@id":"proxyroute-b","match":[{"host":["example.com"]}],"handle":[{"handler":"reverse_proxy"}]}`},
+			args:   args{serverName: "srv0", legacyID: "host:example.com"},
+			want: want{
+				routes: []string{routeB},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"NotReverseProxy": {
+			reason: "A route that isn't a reverse proxy should not be adopted.",
+			routes: []string{`{"match":[{"host":["example.com"]}],"handle":[{"handler":"file_server"}]}`},
+			args:   args{serverName: "srv0", legacyID: "host:example.com"},
+			want: want{
+				routes: []string{""},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"CatchAll": {
+			reason: "No route should be adopted by the legacy ID of a route without matchers.",
+			routes: []string{`{"handle":[{"handler":"reverse_proxy"}]}`},
+			args:   args{serverName: "srv0", legacyID: "default"},
+			want: want{
+				routes: []string{""},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"NoMatch": {
+			reason: "An empty ID should be returned if no route matches the legacy ID.",
+			routes: []string{legacy},
+			args:   args{serverName: "srv0", legacyID: "host:example.org"},
+			want: want{
+				routes: []string{""},
+				check:  func(err error) bool { return err == nil },
+			},
+		},
+		"ServerNotFound": {
+			reason: "An empty ID should be returned if the server doesn't exist.",
+			args:   args{serverName: "srv1", legacyID: "host:example.com"},
+			want:   want{check: func(err error) bool { return err == nil }},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := `{"apps":{"http":{"servers":{"srv0":{"routes":[` + strings.Join(tc.routes, ",") + `]}}}}}`
+			srv := fake.NewServer(fake.WithConfig(cfg))
+			defer srv.Close()
+
+			id, err := newClient(t, srv.URL).AdoptLegacyRoute(context.Background(), tc.args.serverName, tc.args.legacyID, routeA)
+			if !tc.want.check(err) {
+				t.Errorf("\n%s\nc.AdoptLegacyRoute(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("\n%s\nc.AdoptLegacyRoute(...): -want id, +got id:\n%s\n", tc.reason, diff)
+			}
+			var routes []struct {
+				ID string `json:"@id"`
+			}
+			_ = srv.Get("/config/apps/http/servers/"+tc.args.serverName+"/routes", &routes)
+			var ids []string
+			for _, r := range routes {
+				ids = append(ids, r.ID)
+			}
+			if diff := cmp.Diff(tc.want.routes, ids); diff != "" {
+				t.Errorf("\n%s\nc.AdoptLegacyRoute(...): -want @ids, +got @ids:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRouteIndex(t *testing.T) {
+	// byPriority orders routes by the priorities of their @ids. Routes not
+	// listed have priority 0.
+	byPriority := func(priorities map[string]int) caddyclient.Order {
+		return func(a, b *caddyclient.ProxyRoute) bool {
+			return priorities[a.ID] > priorities[b.ID]
+		}
+	}
+	routes := func(ids ...string) []caddyclient.ProxyRoute {
+		out := make([]caddyclient.ProxyRoute, len(ids))
+		for i, id := range ids {
+			out[i] = caddyclient.ProxyRoute{ID: id}
+		}
+		return out
+	}
+
+	type want struct {
+		current int
+		ordered int
+	}
+
+	cases := map[string]struct {
+		reason  string
+		routes  []caddyclient.ProxyRoute
+		routeID string
+		order   caddyclient.Order
+		want    want
+	}{
+		"NotFound": {
+			reason:  "A route that isn't among the routes has no index.",
+			routes:  routes("a", "b"),
+			routeID: "c",
+			order:   byPriority(nil),
+			want:    want{current: -1, ordered: -1},
+		},
+		"EqualRankFirst": {
+			reason:  "The first of two routes that rank equally is in order.",
+			routes:  routes("a", "b"),
+			routeID: "a",
+			order:   byPriority(nil),
+			want:    want{current: 0, ordered: 0},
+		},
+		"EqualRankLast": {
+			reason:  "The last of two routes that rank equally is in order.",
+			routes:  routes("a", "b"),
+			routeID: "b",
+			order:   byPriority(nil),
+			want:    want{current: 1, ordered: 1},
+		},
+		"EqualRankBetweenHigherAndLower": {
+			reason:  "A route among routes of equal rank, after higher and before lower ranked routes, is in order.",
+			routes:  routes("high", "a", "b", "c", "low"),
+			routeID: "b",
+			order:   byPriority(map[string]int{"high": 2, "a": 1, "b": 1, "c": 1}),
+			want:    want{current: 2, ordered: 2},
+		},
+		"OutranksEarlier": {
+			reason:  "A route that outranks an earlier route should move before it.",
+			routes:  routes("a", "b", "c"),
+			routeID: "c",
+			order:   byPriority(map[string]int{"c": 1}),
+			want:    want{current: 2, ordered: 0},
+		},
+		"OutrankedByLater": {
+			reason:  "A route outranked by a later route should move after it, and after routes of equal rank.",
+			routes:  routes("a", "b", "c", "d"),
+			routeID: "a",
+			order:   byPriority(map[string]int{"b": 1, "c": 1}),
+			want:    want{current: 0, ordered: 3},
+		},
+		"NoOrder": {
+			reason:  "Without an order every route is in order.",
+			routes:  routes("a", "b"),
+			routeID: "a",
+			want:    want{current: 0, ordered: 0},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			current, ordered := caddyclient.RouteIndex(tc.routes, tc.routeID, tc.order)
+			if diff := cmp.Diff(tc.want, want{current: current, ordered: ordered}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\ncaddyclient.RouteIndex(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package caddy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by the client. Use errors.Is, or the Is* helpers, to test
+// for them.
+var (
+	// ErrNotFound indicates the addressed config object, e.g. the route with
+	// a particular @id, does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict indicates the request conflicts with the current config,
+	// including when the config changed since it was read.
+	ErrConflict = errors.New("conflict")
+
+	// ErrUnauthorized indicates the admin API rejected the client's
+	// credentials, or requires credentials and none were supplied.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrServerNotFound indicates the addressed HTTP server is not configured.
+	ErrServerNotFound = errors.New("server not found")
+
+	// ErrUnavailable indicates the admin API could not be reached or failed
+	// to handle the request. Such errors are usually transient.
+	ErrUnavailable = errors.New("admin API unavailable")
+
+	// ErrInvalidConfig indicates Caddy rejected the request, usually because
+	// the config it would produce is invalid.
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrInvalidTLSConfig indicates the TLS options the client was created
+	// with are invalid, e.g. because its CA bundle or client certificate
+	// can't be parsed.
+	ErrInvalidTLSConfig = errors.New("invalid TLS config")
+)
+
+// An APIError is returned when the Caddy admin API responds with a non-2xx
+// status code. It wraps the error that categorizes the status code.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Message is the error message Caddy returned, or the response body if
+	// it did not contain one.
+	Message string
+
+	kind error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("caddy API returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the error that categorizes the APIError.
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// newAPIError returns an *APIError for a response with the supplied status
+// code and body. Caddy returns errors as a JSON object with an "error" key.
+func newAPIError(code int, body []byte) *APIError {
+	msg := strings.TrimSpace(string(body))
+	var parsed struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != "" {
+		msg = parsed.Error
+	}
+
+	var kind error
+	switch {
+	case code == http.StatusNotFound:
+		kind = ErrNotFound
+	case code == http.StatusConflict, code == http.StatusPreconditionFailed:
+		kind = ErrConflict
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		kind = ErrUnauthorized
+	case code == http.StatusTooManyRequests, code >= 500:
+		kind = ErrUnavailable
+	default:
+		kind = ErrInvalidConfig
+	}
+
+	return &APIError{StatusCode: code, Message: msg, kind: kind}
+}
+
+// serverError returns err categorized as ErrServerNotFound if Caddy rejected
+// a request addressing a server's routes because the server does not exist.
+// Caddy reports any path into the config that does not exist as an invalid
+// traversal.
+func serverError(serverName string, err error) error {
+	var ae *APIError
+	if !errors.As(err, &ae) || !errors.Is(ae, ErrInvalidConfig) || !strings.Contains(ae.Message, "invalid traversal path") {
+		return err
+	}
+	return &APIError{
+		StatusCode: ae.StatusCode,
+		Message:    fmt.Sprintf("server %q: %s", serverName, ae.Message),
+		kind:       ErrServerNotFound,
+	}
+}
+
+// hasStatus reports whether err is an *APIError with the supplied status code.
+func hasStatus(err error, code int) bool {
+	var ae *APIError
+	return errors.As(err, &ae) && ae.StatusCode == code
+}
+
+// IsNotFound reports whether err indicates the addressed config object does
+// not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err indicates the request conflicts with the
+// current config.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsUnauthorized reports whether err indicates that the admin API rejected
+// the client's credentials, or that it requires credentials and none were
+// supplied.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsServerNotFound reports whether err indicates the addressed HTTP server is
+// not configured.
+func IsServerNotFound(err error) bool {
+	return errors.Is(err, ErrServerNotFound)
+}
+
+// IsUnavailable reports whether err indicates the admin API could not be
+// reached or failed to handle the request.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// IsInvalidConfig reports whether err indicates Caddy rejected the request
+// as invalid.
+func IsInvalidConfig(err error) bool {
+	return errors.Is(err, ErrInvalidConfig)
+}
+
+// IsInvalidTLSConfig reports whether err indicates the TLS options the client
+// was created with are invalid.
+func IsInvalidTLSConfig(err error) bool {
+	return errors.Is(err, ErrInvalidTLSConfig)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package caddy
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/test"
+)
+
+func TestNewAPIError(t *testing.T) {
+	type args struct {
+		code int
+		body string
+	}
+	type want struct {
+		code int
+		msg  string
+		kind error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "A 404 should be categorized as ErrNotFound.",
+			args:   args{code: http.StatusNotFound, body: `{"error":"unknown object ID 'proxyroute-a'"}`},
+			want:   want{code: http.StatusNotFound, msg: "unknown object ID 'proxyroute-a'", kind: ErrNotFound},
+		},
+		"Conflict": {
+			reason: "A 409 should be categorized as ErrConflict.",
+			args:   args{code: http.StatusConflict, body: `{"error":"key already exists: routes"}`},
+			want:   want{code: http.StatusConflict, msg: "key already exists: routes", kind: ErrConflict},
+		},
+		"PreconditionFailed": {
+			reason: "A 412, which Caddy returns when an If-Match check fails, should be categorized as ErrConflict.",
+			args:   args{code: http.StatusPreconditionFailed, body: `{"error":"If-Match header did not match current config hash"}`},
+			want:   want{code: http.StatusPreconditionFailed, msg: "If-Match header did not match current config hash", kind: ErrConflict},
+		},
+		"Unauthorized": {
+			reason: "A 401 should be categorized as ErrUnauthorized.",
+			args:   args{code: http.StatusUnauthorized, body: `{"error":"unauthorized"}`},
+			want:   want{code: http.StatusUnauthorized, msg: "unauthorized", kind: ErrUnauthorized},
+		},
+		"Forbidden": {
+			reason: "A 403 should be categorized as ErrUnauthorized.",
+			args:   args{code: http.StatusForbidden, body: `{"error":"host not allowed: caddy"}`},
+			want:   want{code: http.StatusForbidden, msg: "host not allowed: caddy", kind: ErrUnauthorized},
+		},
+		"TooManyRequests": {
+			reason: "A 429 should be categorized as ErrUnavailable.",
+			args:   args{code: http.StatusTooManyRequests, body: `{"error":"slow down"}`},
+			want:   want{code: http.StatusTooManyRequests, msg: "slow down", kind: ErrUnavailable},
+		},
+		"ServerError": {
+			reason: "A 5xx should be categorized as ErrUnavailable.",
+			args:   args{code: http.StatusBadGateway, body: `{"error":"upstream unavailable"}`},
+			want:   want{code: http.StatusBadGateway, msg: "upstream unavailable", kind: ErrUnavailable},
+		},
+		"BadRequest": {
+			reason: "A 400, which Caddy returns when it rejects a config, should be categorized as ErrInvalidConfig.",
+			args:   args{code: http.StatusBadRequest, body: `{"error":"loading new config: unknown handler"}`},
+			want:   want{code: http.StatusBadRequest, msg: "loading new config: unknown handler", kind: ErrInvalidConfig},
+		},
+		"OtherClientError": {
+			reason: "Any other 4xx should be categorized as ErrInvalidConfig.",
+			args:   args{code: http.StatusMethodNotAllowed, body: `{"error":"method not allowed"}`},
+			want:   want{code: http.StatusMethodNotAllowed, msg: "method not allowed", kind: ErrInvalidConfig},
+		},
+		"PlainTextBody": {
+			reason: "A body that isn't JSON, e.g. from a proxy in front of Caddy, should be used as the message.",
+			args:   args{code: http.StatusServiceUnavailable, body: "no healthy upstream\n"},
+			want:   want{code: http.StatusServiceUnavailable, msg: "no healthy upstream", kind: ErrUnavailable},
+		},
+		"JSONWithoutError": {
+			reason: "A JSON body without an error message should be used as the message.",
+			args:   args{code: http.StatusBadRequest, body: `{"message":"bad"}`},
+			want:   want{code: http.StatusBadRequest, msg: `{"message":"bad"}`, kind: ErrInvalidConfig},
+		},
+		"EmptyBody": {
+			reason: "An empty body should result in an empty message.",
+			args:   args{code: http.StatusInternalServerError},
+			want:   want{code: http.StatusInternalServerError, kind: ErrUnavailable},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := newAPIError(tc.args.code, []byte(tc.args.body))
+			got := want{code: e.StatusCode, msg: e.Message, kind: e.Unwrap()}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nnewAPIError(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestServerError(t *testing.T) {
+	traversal := newAPIError(http.StatusBadRequest, []byte(`{"error":"invalid traversal path at: config/apps/http/servers/srv1"}`))
+	invalid := newAPIError(http.StatusBadRequest, []byte(`{"error":"loading new config: unknown handler"}`))
+	notFound := newAPIError(http.StatusNotFound, []byte(`{"error":"invalid traversal path at: config/apps/http/servers/srv1"}`))
+	unavailable := errors.New("connection refused")
+
+	type want struct {
+		err  error
+		kind error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"InvalidTraversal": {
+			reason: "An invalid traversal of a server's routes should be categorized as ErrServerNotFound.",
+			err:    traversal,
+			want: want{
+				err:  &APIError{StatusCode: http.StatusBadRequest, Message: `server "srv1": invalid traversal path at: config/apps/http/servers/srv1`},
+				kind: ErrServerNotFound,
+			},
+		},
+		"OtherInvalidConfig": {
+			reason: "Caddy rejecting a request for any other reason should be returned unchanged.",
+			err:    invalid,
+			want:   want{err: invalid, kind: ErrInvalidConfig},
+		},
+		"OtherStatus": {
+			reason: "An invalid traversal reported with a status other than 400 should be returned unchanged.",
+			err:    notFound,
+			want:   want{err: notFound, kind: ErrNotFound},
+		},
+		"NotAPIError": {
+			reason: "An error that isn't an *APIError should be returned unchanged.",
+			err:    unavailable,
+			want:   want{err: unavailable, kind: unavailable},
+		},
+		"NoError": {
+			reason: "No error should be returned unchanged.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := serverError("srv1", tc.err)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nserverError(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.kind != nil && !errors.Is(err, tc.want.kind) {
+				t.Errorf("\n%s\nserverError(...): want error that is %q, got %v", tc.reason, tc.want.kind, err)
+			}
+			if !errors.Is(tc.want.kind, ErrServerNotFound) && IsServerNotFound(err) {
+				t.Errorf("\n%s\nserverError(...): want error that is not %q, got %v", tc.reason, ErrServerNotFound, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package caddy
+
+import (
+	"context"
+	"time"
+)
+
+// DoIfMatch exposes doIfMatch to tests, so that they can exercise retries of
+// requests the Client's API never sends without If-Match.
+func (c *Client) DoIfMatch(ctx context.Context, method, path, ifMatch string, in, out any) (string, error) {
+	return c.doIfMatch(ctx, method, path, ifMatch, in, out)
+}
+
+// Backoff exposes backoff to tests.
+func (c *Client) Backoff(attempt int) time.Duration {
+	return c.backoff(attempt)
+}
@@ -0,0 +1,555 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides an in-process fake of the Caddy admin API, for
+// exercising the Caddy client and the controllers that use it without a real
+// Caddy binary.
+package fake
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+// DefaultConfig is the config a Server starts with unless WithConfig is
+// supplied: a single HTTP server named "srv0" without routes.
+const DefaultConfig = `{"apps":{"http":{"servers":{"srv0":{"listen":[":80"],"routes":[]}}}}}`
+
+// A Server is a fake Caddy admin API. It implements the semantics of Caddy's
+// config API for arbitrary paths below /config/, including array appends and
+// inserts, @id lookups through /id/, and Etag / If-Match concurrency control,
+// as well as /reverse_proxy/upstreams. Errors are returned as Caddy returns
+// them, i.e. as a JSON object with an "error" key.
+//
+// Like Caddy, the Server reports most invalid requests, including paths that
+// don't exist, as 400 Bad Request; only unknown @ids are 404 Not Found.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	root      map[string]any
+	ids       map[string]string
+	upstreams []Upstream
+	token     string
+	tls       *tls.Config
+	socket    string
+	faults    []Fault
+	requests  []string
+}
+
+// A Fault is injected into the Server's handling of a request, to simulate
+// an admin API that is failing or unreachable.
+type Fault struct {
+	// Method is the request method the Fault applies to. Any method matches
+	// if it is empty.
+	Method string
+
+	// Delay delays handling the request, e.g. to make a client time out.
+	Delay time.Duration
+
+	// StatusCode is returned instead of handling the request, if non-zero.
+	StatusCode int
+
+	// Drop closes the connection without responding, unless StatusCode is
+	// set.
+	Drop bool
+
+	// Apply handles the request before the connection is dropped, i.e. the
+	// request succeeds but its response is lost.
+	Apply bool
+}
+
+// An Option configures a Server.
+type Option func(s *Server)
+
+// WithConfig starts the Server with the supplied JSON encoded config. It
+// panics if the config is not valid JSON.
+func WithConfig(cfg string) Option {
+	return func(s *Server) {
+		var v any
+		if err := json.Unmarshal([]byte(cfg), &v); err != nil {
+			panic(fmt.Sprintf("invalid config: %v", err))
+		}
+		s.root["config"] = v
+	}
+}
+
+// An Upstream is an upstream as Caddy reports it at /reverse_proxy/upstreams.
+// Caddy reports no other fields; in particular, it doesn't report whether an
+// upstream is healthy.
+type Upstream struct {
+	// Address is the upstream's dial address.
+	Address string `json:"address"`
+
+	// NumRequests is how many requests the upstream is handling.
+	NumRequests int `json:"num_requests"`
+
+	// Fails is how many failed requests to the upstream passive health
+	// checks currently remember.
+	Fails int `json:"fails"`
+}
+
+// WithUpstreams makes the Server report the supplied upstreams at
+// /reverse_proxy/upstreams.
+func WithUpstreams(u ...Upstream) Option {
+	return func(s *Server) {
+		s.upstreams = u
+	}
+}
+
+// WithBearerToken makes the Server reject requests that don't present the
+// supplied bearer token with 401 Unauthorized, like an authenticating proxy
+// in front of the admin API would.
+func WithBearerToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithTLS makes the Server serve HTTPS using a certificate for "example.com"
+// and the loopback addresses, issued by the CA returned by the Server's
+// Certificate method. The supplied config, which may be used to require client
+// certificates, is used as is except for the certificate.
+func WithTLS(cfg *tls.Config) Option {
+	return func(s *Server) {
+		s.tls = cfg
+	}
+}
+
+// WithUnixSocket makes the Server listen on a Unix domain socket at the
+// supplied path rather than on a loopback TCP port. Like Caddy, it then
+// rejects requests whose Host header is neither empty nor a loopback IP with
+// 403 Forbidden. Clients reach the Server at "unix/" + path.
+func WithUnixSocket(path string) Option {
+	return func(s *Server) {
+		s.socket = path
+	}
+}
+
+// NewServer starts and returns a new Server. Callers should call Close when
+// finished, to shut it down.
+func NewServer(opts ...Option) *Server {
+	s := &Server{root: map[string]any{}}
+	WithConfig(DefaultConfig)(s)
+	for _, o := range opts {
+		o(s)
+	}
+	ids, err := index(s.root)
+	if err != nil {
+		panic(err.Error())
+	}
+	s.ids = ids
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	if s.socket != "" {
+		l, err := net.Listen("unix", s.socket)
+		if err != nil {
+			panic(fmt.Sprintf("cannot listen on %s: %v", s.socket, err))
+		}
+		_ = s.Server.Listener.Close()
+		s.Server.Listener = l
+	}
+	if s.tls != nil {
+		// Failed handshakes are expected when testing TLS, and would
+		// otherwise be logged.
+		s.Server.Config.ErrorLog = log.New(io.Discard, "", 0)
+		s.Server.TLS = s.tls
+		s.StartTLS()
+		return s
+	}
+	s.Start()
+	return s
+}
+
+// SetUpstreams replaces the upstreams the Server reports.
+func (s *Server) SetUpstreams(u ...Upstream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upstreams = u
+}
+
+// Inject queues the supplied Faults. Each request that matches the Fault at
+// the head of the queue is subject to it, and removes it from the queue.
+func (s *Server) Inject(f ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, f...)
+}
+
+// Requests returns the method and path, e.g. "GET /config/", of each request
+// the Server received, in the order it received them.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Get decodes the config at the supplied path, e.g.
+// "/config/apps/http/servers/srv0", into out.
+func (s *Server) Get(path string, out any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := access(s.root, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// Routes returns the routes of the supplied server. It returns nil if the
+// server does not exist.
+func (s *Server) Routes(serverName string) []caddyclient.ProxyRoute {
+	var routes []caddyclient.ProxyRoute
+	if err := s.Get("/config/apps/http/servers/"+serverName+"/routes", &routes); err != nil {
+		return nil
+	}
+	return routes
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f, ok := s.fault(r)
+	if !ok {
+		s.handle(w, r)
+		return
+	}
+
+	select {
+	case <-time.After(f.Delay):
+	case <-r.Context().Done():
+		return
+	}
+	switch {
+	case f.StatusCode != 0:
+		writeError(w, f.StatusCode, http.StatusText(f.StatusCode))
+	case f.Drop:
+		if f.Apply {
+			s.handle(httptest.NewRecorder(), r)
+		}
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				_ = conn.Close()
+			}
+		}
+	default:
+		s.handle(w, r)
+	}
+}
+
+// fault records the supplied request, and returns the Fault it is subject to,
+// if any.
+func (s *Server) fault(r *http.Request) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	if len(s.faults) == 0 || (s.faults[0].Method != "" && s.faults[0].Method != r.Method) {
+		return Fault{}, false
+	}
+	f := s.faults[0]
+	s.faults = s.faults[1:]
+	return f, true
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.socket != "" && r.Host != "" && r.Host != "127.0.0.1" && r.Host != "[::1]" {
+		writeError(w, http.StatusForbidden, "host not allowed: "+r.Host)
+		return
+	}
+
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/reverse_proxy/upstreams":
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		upstreams := s.upstreams
+		if upstreams == nil {
+			upstreams = []Upstream{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(upstreams)
+	case strings.HasPrefix(r.URL.Path, "/id/"):
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/id/"), "/", 2)
+		expanded, ok := s.ids[parts[0]]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("unknown object ID '%s'", parts[0]))
+			return
+		}
+		if len(parts) == 2 {
+			expanded += "/" + parts[1]
+		}
+		s.serveConfig(w, r, expanded)
+	case r.URL.Path == "/config" || strings.HasPrefix(r.URL.Path, "/config/"):
+		s.serveConfig(w, r, r.URL.Path)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) serveConfig(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method == http.MethodGet {
+		v, err := access(s.root, http.MethodGet, path, nil)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		b, _ := json.Marshal(v)
+		b = append(b, '\n')
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Etag", etag(path, b))
+		_, _ = w.Write(b)
+		return
+	}
+
+	var val any
+	if r.Method != http.MethodDelete {
+		if !strings.Contains(r.Header.Get("Content-Type"), "/json") {
+			writeError(w, http.StatusBadRequest, "unacceptable content-type: "+r.Header.Get("Content-Type"))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &val); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("decoding request body: %v", err))
+			return
+		}
+	}
+
+	if m := r.Header.Get("If-Match"); m != "" {
+		if code, msg := s.checkIfMatch(m); code != 0 {
+			writeError(w, code, msg)
+			return
+		}
+	}
+
+	// Mutate a copy of the config so that it can be discarded if the result
+	// is invalid, as Caddy rolls back config changes that fail to load.
+	root := deepCopy(s.root)
+	if _, err := access(root, r.Method, path, val); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ids, err := index(root)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.root, s.ids = root, ids
+}
+
+// checkIfMatch checks the supplied If-Match header, which Caddy expects to be
+// an Etag it returned, i.e. a quoted path and hash of the config at that path.
+// It returns a non-zero status code and message if the check fails.
+func (s *Server) checkIfMatch(header string) (int, string) {
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return http.StatusBadRequest, "malformed If-Match header; expect quoted string"
+	}
+	parts := strings.Fields(header[1 : len(header)-1])
+	if len(parts) != 2 {
+		return http.StatusBadRequest, "malformed If-Match header; expect format \"<path> <hash>\""
+	}
+	v, err := access(s.root, http.MethodGet, parts[0], nil)
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	b, _ := json.Marshal(v)
+	b = append(b, '\n')
+	if etag(parts[0], b) != header {
+		return http.StatusPreconditionFailed, "If-Match header did not match current config hash"
+	}
+	return 0, ""
+}
+
+// access performs the supplied method on the config at path, relative to the
+// root of the config tree, the way Caddy does. For GET it returns the value at
+// the path; other methods modify root in place.
+func access(root map[string]any, method, path string, val any) (any, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var out any
+	_, err := apply(root, parts, 0, method, val, &out)
+	return out, err
+}
+
+// apply performs the supplied method on parts[i:], relative to node, and
+// returns node as modified. It returns the value at the path in out for GET.
+//
+//nolint:gocyclo // Mirrors Caddy's config traversal, which is a big switch.
+func apply(node any, parts []string, i int, method string, val any, out *any) (any, error) {
+	part := parts[i]
+	last := i == len(parts)-1
+	path := strings.Join(parts, "/")
+
+	switch v := node.(type) {
+	case map[string]any:
+		existing, exists := v[part]
+		if !last {
+			child, err := apply(existing, parts, i+1, method, val, out)
+			if err != nil {
+				return nil, err
+			}
+			if method != http.MethodGet {
+				v[part] = child
+			}
+			return v, nil
+		}
+		switch method {
+		case http.MethodGet:
+			*out = existing
+		case http.MethodPost:
+			if arr, ok := existing.([]any); ok {
+				v[part] = append(arr, val)
+			} else {
+				v[part] = val
+			}
+		case http.MethodPut:
+			if exists {
+				return nil, fmt.Errorf("[/%s] key already exists: %s", path, part)
+			}
+			v[part] = val
+		case http.MethodPatch:
+			if !exists {
+				return nil, fmt.Errorf("[/%s] key does not exist: %s", path, part)
+			}
+			v[part] = val
+		case http.MethodDelete:
+			if !exists {
+				return nil, fmt.Errorf("[/%s] key does not exist: %s", path, part)
+			}
+			delete(v, part)
+		default:
+			return nil, fmt.Errorf("unrecognized method %s", method)
+		}
+		return v, nil
+
+	case []any:
+		idx, err := strconv.Atoi(part)
+		// Like Caddy, PUT may insert at the end of an array, but only when
+		// the array is the last element of the path.
+		allowAppend := last && method == http.MethodPut && idx == len(v)
+		if err != nil || idx < 0 || (!allowAppend && idx >= len(v)) {
+			return nil, fmt.Errorf("[/%s] invalid array index '%s': %v", path, part, err)
+		}
+		if !last {
+			child, err := apply(v[idx], parts, i+1, method, val, out)
+			if err != nil {
+				return nil, err
+			}
+			if method != http.MethodGet {
+				v[idx] = child
+			}
+			return v, nil
+		}
+		switch method {
+		case http.MethodGet:
+			*out = v[idx]
+		case http.MethodPost:
+			if arr, ok := v[idx].([]any); ok {
+				v[idx] = append(arr, val)
+			} else {
+				v[idx] = val
+			}
+		case http.MethodPut:
+			v = append(v, nil)
+			copy(v[idx+1:], v[idx:])
+			v[idx] = val
+		case http.MethodPatch:
+			v[idx] = val
+		case http.MethodDelete:
+			v = append(v[:idx], v[idx+1:]...)
+		default:
+			return nil, fmt.Errorf("unrecognized method %s", method)
+		}
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf("invalid traversal path at: %s", strings.Join(parts[:i+1], "/"))
+	}
+}
+
+// index returns the config path of every object in the config tree that has
+// an @id, keyed by the @id. It fails if an @id is used more than once.
+func index(root map[string]any) (map[string]string, error) {
+	ids := map[string]string{}
+	var walk func(v any, path string) error
+	walk = func(v any, path string) error {
+		switch v := v.(type) {
+		case map[string]any:
+			if id, ok := v["@id"].(string); ok {
+				if existing, dup := ids[id]; dup {
+					return fmt.Errorf("indexing config: duplicate ID '%s' found at %s and %s", id, existing, path)
+				}
+				ids[id] = path
+			}
+			for k, child := range v {
+				if err := walk(child, path+"/"+k); err != nil {
+					return err
+				}
+			}
+		case []any:
+			for i, child := range v {
+				if err := walk(child, path+"/"+strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return ids, walk(root["config"], "/config")
+}
+
+// etag returns the Etag Caddy would return for the supplied config, read from
+// the supplied path.
+func etag(path string, b []byte) string {
+	h := sha256.Sum256(append([]byte(path), b...))
+	return fmt.Sprintf(`"%s %s"`, path, hex.EncodeToString(h[:8]))
+}
+
+func deepCopy(in map[string]any) map[string]any {
+	b, _ := json.Marshal(in)
+	out := map[string]any{}
+	_ = json.NewDecoder(bytes.NewReader(b)).Decode(&out)
+	return out
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
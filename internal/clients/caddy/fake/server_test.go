@@ -0,0 +1,186 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServeConfig(t *testing.T) {
+	type want struct {
+		code int
+		cfg  string
+	}
+
+	cases := map[string]struct {
+		reason string
+		method string
+		path   string
+		body   string
+		want   want
+	}{
+		"PutAppend": {
+			reason: "PUT should insert at the end of an array when the index is its length.",
+			method: http.MethodPut,
+			path:   "/config/apps/http/servers/srv0/routes/0",
+			body:   `{"@id":"a"}`,
+			want:   want{code: http.StatusOK, cfg: `[{"@id":"a"}]`},
+		},
+		"PutPastEndOfArray": {
+			reason: "PUT should not traverse through an index past the end of an array.",
+			method: http.MethodPut,
+			path:   "/config/apps/http/servers/srv0/routes/0/@id",
+			body:   `"a"`,
+			want:   want{code: http.StatusBadRequest, cfg: `[]`},
+		},
+		"PatchPastEndOfArray": {
+			reason: "PATCH should not replace an index past the end of an array.",
+			method: http.MethodPatch,
+			path:   "/config/apps/http/servers/srv0/routes/0",
+			body:   `{"@id":"a"}`,
+			want:   want{code: http.StatusBadRequest, cfg: `[]`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+
+			req, err := http.NewRequest(tc.method, s.URL+tc.path, bytes.NewBufferString(tc.body)) //nolint:noctx // Test only.
+			if err != nil {
+				t.Fatalf("http.NewRequest(...): %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatalf("\n%s\n%s %s: %v", tc.reason, tc.method, tc.path, err)
+			}
+			_ = resp.Body.Close()
+			if diff := cmp.Diff(tc.want.code, resp.StatusCode); diff != "" {
+				t.Errorf("\n%s\n%s %s: -want status, +got status:\n%s\n", tc.reason, tc.method, tc.path, diff)
+			}
+
+			var routes any
+			if err := s.Get("/config/apps/http/servers/srv0/routes", &routes); err != nil {
+				t.Fatalf("s.Get(...): %v", err)
+			}
+			b, _ := json.Marshal(routes)
+			if diff := cmp.Diff(tc.want.cfg, string(b)); diff != "" {
+				t.Errorf("\n%s\n%s %s: -want routes, +got routes:\n%s\n", tc.reason, tc.method, tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestUnixSocketHost(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		host   string
+		want   int
+	}{
+		"IPv4Loopback": {
+			reason: "Caddy accepts requests for 127.0.0.1 on a Unix socket.",
+			host:   "127.0.0.1",
+			want:   http.StatusOK,
+		},
+		"IPv6Loopback": {
+			reason: "Caddy accepts requests for ::1 on a Unix socket.",
+			host:   "[::1]",
+			want:   http.StatusOK,
+		},
+		"OtherHost": {
+			reason: "Caddy rejects requests for any other host on a Unix socket.",
+			host:   "caddy:2019",
+			want:   http.StatusForbidden,
+		},
+	}
+
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	s := NewServer(WithUnixSocket(socket))
+	defer s.Close()
+
+	d := &net.Dialer{}
+	c := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, "unix", socket)
+	}}}
+	defer c.CloseIdleConnections()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://"+tc.host+"/config/", nil) //nolint:noctx // Test only.
+			if err != nil {
+				t.Fatalf("http.NewRequest(...): %v", err)
+			}
+			resp, err := c.Do(req)
+			if err != nil {
+				t.Fatalf("\n%s\nGET /config/: %v", tc.reason, err)
+			}
+			_ = resp.Body.Close()
+			if diff := cmp.Diff(tc.want, resp.StatusCode); diff != "" {
+				t.Errorf("\n%s\nGET /config/: -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestServeUpstreams(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		upstreams []Upstream
+		want      string
+	}{
+		"NoUpstreams": {
+			reason: "Caddy reports an empty array when there are no upstreams.",
+			want:   `[]`,
+		},
+		"Upstreams": {
+			reason: "Caddy reports only the address, number of requests, and fails of each upstream.",
+			upstreams: []Upstream{
+				{Address: "10.0.0.1:8080", NumRequests: 2},
+				{Address: "10.0.0.2:8080", Fails: 1},
+			},
+			want: `[{"address":"10.0.0.1:8080","num_requests":2,"fails":0},{"address":"10.0.0.2:8080","num_requests":0,"fails":1}]`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewServer(WithUpstreams(tc.upstreams...))
+			defer s.Close()
+
+			resp, err := s.Client().Get(s.URL + "/reverse_proxy/upstreams") //nolint:noctx // Test only.
+			if err != nil {
+				t.Fatalf("\n%s\nGET /reverse_proxy/upstreams: %v", tc.reason, err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			b, _ := io.ReadAll(resp.Body)
+			if diff := cmp.Diff(tc.want, string(bytes.TrimSpace(b))); diff != "" {
+				t.Errorf("\n%s\nGET /reverse_proxy/upstreams: -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
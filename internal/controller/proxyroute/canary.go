@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/event"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+const (
+	policyWeightedRoundRobin = "weighted_round_robin"
+
+	// Defaults of a progressive canary.
+	defaultCanaryStepWeight   = 10
+	defaultCanaryStepInterval = 5 * time.Minute
+	defaultCanaryMaxWeight    = 100
+
+	// minCanaryPollInterval bounds how soon a progressing canary is
+	// reconciled again, in case its next step is due.
+	minCanaryPollInterval = time.Second
+
+	reasonCanaryProgressed event.Reason = "CanaryProgressed"
+	reasonCanaryPromoted   event.Reason = "CanaryPromoted"
+	reasonCanaryRolledBack event.Reason = "CanaryRolledBack"
+)
+
+// canaryWeight returns the percentage of traffic the supplied ProxyRoute's
+// canary group should currently receive.
+func canaryWeight(p *v1alpha1.ProxyRouteParameters, o *v1alpha1.ProxyRouteObservation) int {
+	switch {
+	case p.Canary == nil:
+		return 0
+	case p.Canary.Progressive == nil:
+		return ptr.Deref(p.Canary.Weight, 0)
+	case o.Canary == nil || o.Canary.Revision != canaryRevision(p.Canary):
+		return 0
+	default:
+		return o.Canary.Weight
+	}
+}
+
+// canaryRevision identifies the upstreams of the supplied canary group. It
+// changes when they do, but not when the endpoints of the Services they're
+// discovered from do.
+func canaryRevision(c *v1alpha1.Canary) string {
+	b, _ := json.Marshal(struct {
+		Upstreams     []v1alpha1.Upstream       `json:"upstreams,omitempty"`
+		UpstreamsFrom []v1alpha1.UpstreamSource `json:"upstreamsFrom,omitempty"`
+	}{c.Upstreams, c.UpstreamsFrom})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// canaryHealthy reports whether none of the supplied canary upstreams have
+// reached the passive health checks' maxFails. Caddy doesn't count failures
+// without passive health checks, so a canary is then always healthy.
+func canaryHealthy(p *v1alpha1.ProxyRouteParameters, canary []weightedUpstream, all []caddyclient.UpstreamStatus) bool {
+	reported := reportedUpstreams(p, all)
+	for _, u := range canary {
+		s, ok := reported[normalizeDial(u.Dial, defaultPortOf(p))]
+		if !ok {
+			continue
+		}
+		if healthy, _ := upstreamHealthy(p, s); !healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// progressCanary updates the observed state of the supplied ProxyRoute's
+// canary group. A progressive canary starts at one step's weight, and its
+// weight increases by a step each step interval until it is promoted. If
+// the canary is unhealthy while progressing it's rolled back to zero weight.
+// It returns an event describing any step taken.
+func progressCanary(p *v1alpha1.ProxyRouteParameters, o *v1alpha1.ProxyRouteObservation, healthy bool, now metav1.Time) *event.Event {
+	c := p.Canary
+	if c == nil {
+		o.Canary = nil
+		return nil
+	}
+	rev := canaryRevision(c)
+	if c.Progressive == nil {
+		o.Canary = &v1alpha1.CanaryObservation{Revision: rev, Weight: ptr.Deref(c.Weight, 0)}
+		return nil
+	}
+
+	step := ptr.Deref(c.Progressive.StepWeight, defaultCanaryStepWeight)
+	maxWeight := ptr.Deref(c.Progressive.MaxWeight, defaultCanaryMaxWeight)
+
+	s := o.Canary
+	if s == nil || s.Revision != rev || s.Phase == "" {
+		o.Canary = &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: min(step, maxWeight), LastStepTime: &now}
+		return stepEvent(o.Canary, maxWeight)
+	}
+
+	switch s.Phase {
+	case v1alpha1.CanaryPhaseProgressing:
+		if !healthy {
+			s.Phase, s.Weight, s.LastStepTime = v1alpha1.CanaryPhaseRolledBack, 0, &now
+			e := event.Warning(reasonCanaryRolledBack, errors.New("rolled back canary because its upstreams are unhealthy"))
+			return &e
+		}
+		if s.LastStepTime != nil && now.Sub(s.LastStepTime.Time) < canaryStepInterval(c) {
+			return nil
+		}
+		s.Weight, s.LastStepTime = min(s.Weight+step, maxWeight), &now
+		return stepEvent(s, maxWeight)
+	case v1alpha1.CanaryPhasePromoted:
+		// Follow changes to the maximum weight. Resume progressing if it
+		// was raised.
+		if s.Weight > maxWeight {
+			s.Weight = maxWeight
+		}
+		if s.Weight < maxWeight {
+			s.Phase = v1alpha1.CanaryPhaseProgressing
+		}
+	}
+	return nil
+}
+
+// stepEvent returns an event describing a step of a progressive canary to
+// its current weight, promoting it if it has reached the supplied maximum.
+func stepEvent(s *v1alpha1.CanaryObservation, maxWeight int) *event.Event {
+	if s.Weight >= maxWeight {
+		s.Phase = v1alpha1.CanaryPhasePromoted
+	}
+	e := event.Normal(reasonCanaryProgressed, fmt.Sprintf("Shifted %d%% of traffic to canary", s.Weight))
+	if s.Phase == v1alpha1.CanaryPhasePromoted {
+		e = event.Normal(reasonCanaryPromoted, fmt.Sprintf("Promoted canary with %d%% of traffic", s.Weight))
+	}
+	return &e
+}
+
+func canaryStepInterval(c *v1alpha1.Canary) time.Duration {
+	if d := c.Progressive.StepInterval; d != nil {
+		return d.Duration
+	}
+	return defaultCanaryStepInterval
+}
+
+// canaryPollInterval returns how long to wait before reconciling the supplied
+// ProxyRoute again. A progressing canary is reconciled when its next step is
+// due, if that is sooner than the supplied poll interval.
+func canaryPollInterval(mg resource.Managed, pollInterval time.Duration) time.Duration {
+	p, o, err := proxyRouteOf(mg)
+	if err != nil || p.Canary == nil || p.Canary.Progressive == nil || o.Canary == nil {
+		return pollInterval
+	}
+	if o.Canary.Phase != v1alpha1.CanaryPhaseProgressing || o.Canary.LastStepTime == nil {
+		return pollInterval
+	}
+	due := time.Until(o.Canary.LastStepTime.Add(canaryStepInterval(p.Canary)))
+	return max(min(due, pollInterval), minCanaryPollInterval)
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/event"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	"github.com/crossplane/provider-caddy/internal/clients/caddy/fake"
+)
+
+func TestProgressCanary(t *testing.T) {
+	now := metav1.NewTime(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	ago := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-d))
+		return &t
+	}
+
+	fixed := &v1alpha1.Canary{Upstreams: []v1alpha1.Upstream{{Dial: "canary:80"}}, Weight: ptr.To(30)}
+	progressive := &v1alpha1.Canary{Upstreams: []v1alpha1.Upstream{{Dial: "canary:80"}}, Progressive: &v1alpha1.ProgressiveCanary{}}
+	capped := &v1alpha1.Canary{Upstreams: []v1alpha1.Upstream{{Dial: "canary:80"}}, Progressive: &v1alpha1.ProgressiveCanary{MaxWeight: ptr.To(50)}}
+	rev := canaryRevision(progressive)
+
+	progressed := func(w int) *event.Event {
+		e := event.Normal(reasonCanaryProgressed, fmt.Sprintf("Shifted %d%% of traffic to canary", w))
+		return &e
+	}
+	promoted := func(w int) *event.Event {
+		e := event.Normal(reasonCanaryPromoted, fmt.Sprintf("Promoted canary with %d%% of traffic", w))
+		return &e
+	}
+	rolledBack := event.Warning(reasonCanaryRolledBack, errors.New("rolled back canary because its upstreams are unhealthy"))
+
+	type args struct {
+		canary  *v1alpha1.Canary
+		o       *v1alpha1.CanaryObservation
+		healthy bool
+	}
+	type want struct {
+		o *v1alpha1.CanaryObservation
+		e *event.Event
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoCanary": {
+			reason: "The canary observation should be cleared when there is no canary group.",
+			args:   args{o: &v1alpha1.CanaryObservation{Weight: 10}, healthy: true},
+			want:   want{},
+		},
+		"FixedWeight": {
+			reason: "A canary group with a fixed weight should be observed at that weight.",
+			args:   args{canary: fixed, healthy: true},
+			want:   want{o: &v1alpha1.CanaryObservation{Revision: canaryRevision(fixed), Weight: 30}},
+		},
+		"Start": {
+			reason: "A progressive canary should start at one step's weight.",
+			args:   args{canary: progressive, healthy: true},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 10, LastStepTime: &now},
+				e: progressed(10),
+			},
+		},
+		"NotDue": {
+			reason: "A progressive canary should not step before its step interval has passed.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 10, LastStepTime: ago(time.Minute)},
+				healthy: true,
+			},
+			want: want{o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 10, LastStepTime: ago(time.Minute)}},
+		},
+		"Step": {
+			reason: "A progressive canary should step once its step interval has passed.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 10, LastStepTime: ago(5 * time.Minute)},
+				healthy: true,
+			},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 20, LastStepTime: &now},
+				e: progressed(20),
+			},
+		},
+		"Promote": {
+			reason: "A progressive canary should be promoted when it reaches its maximum weight.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 90, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhasePromoted, Weight: 100, LastStepTime: &now},
+				e: promoted(100),
+			},
+		},
+		"PromoteCapped": {
+			reason: "A progressive canary's last step should not exceed its maximum weight.",
+			args: args{
+				canary:  capped,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 45, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhasePromoted, Weight: 50, LastStepTime: &now},
+				e: promoted(50),
+			},
+		},
+		"RollBack": {
+			reason: "An unhealthy progressing canary should be rolled back to zero weight.",
+			args: args{
+				canary: progressive,
+				o:      &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 30, LastStepTime: ago(time.Minute)},
+			},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseRolledBack, Weight: 0, LastStepTime: &now},
+				e: &rolledBack,
+			},
+		},
+		"StayRolledBack": {
+			reason: "A rolled back canary should stay rolled back when it becomes healthy again.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseRolledBack, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseRolledBack, LastStepTime: ago(time.Hour)}},
+		},
+		"RestartOnNewRevision": {
+			reason: "A rolled back canary should start over when its upstreams change.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: "old", Phase: v1alpha1.CanaryPhaseRolledBack, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{
+				o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 10, LastStepTime: &now},
+				e: progressed(10),
+			},
+		},
+		"ResumeWhenMaxRaised": {
+			reason: "A promoted canary should resume progressing when its maximum weight is raised.",
+			args: args{
+				canary:  progressive,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhasePromoted, Weight: 50, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhaseProgressing, Weight: 50, LastStepTime: ago(time.Hour)}},
+		},
+		"FollowMaxLowered": {
+			reason: "A promoted canary should follow its maximum weight when it is lowered.",
+			args: args{
+				canary:  capped,
+				o:       &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhasePromoted, Weight: 100, LastStepTime: ago(time.Hour)},
+				healthy: true,
+			},
+			want: want{o: &v1alpha1.CanaryObservation{Revision: rev, Phase: v1alpha1.CanaryPhasePromoted, Weight: 50, LastStepTime: ago(time.Hour)}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &v1alpha1.ProxyRouteParameters{Canary: tc.args.canary}
+			o := &v1alpha1.ProxyRouteObservation{Canary: tc.args.o}
+			e := progressCanary(p, o, tc.args.healthy, now)
+			if diff := cmp.Diff(tc.want.o, o.Canary); diff != "" {
+				t.Errorf("\n%s\nprogressCanary(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.e, e); diff != "" {
+				t.Errorf("\n%s\nprogressCanary(...): -want event, +got event:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveCanary(t *testing.T) {
+	withCanary := func(cr *v1alpha1.ProxyRoute) {
+		cr.Spec.ForProvider.Canary = &v1alpha1.Canary{Upstreams: []v1alpha1.Upstream{{Dial: "canary:80"}}, Progressive: &v1alpha1.ProgressiveCanary{}}
+	}
+	withPassive := func(cr *v1alpha1.ProxyRoute) {
+		cr.Spec.ForProvider.HealthChecks = &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{FailDuration: ptr.To("30s"), MaxFails: ptr.To(2)}}
+	}
+
+	type want struct {
+		phase  string
+		weight int
+	}
+
+	cases := map[string]struct {
+		reason    string
+		upstreams []fake.Upstream
+		mg        *v1alpha1.ProxyRoute
+		want      want
+	}{
+		"NoHealthChecks": {
+			reason:    "A canary whose health Caddy doesn't report should progress.",
+			upstreams: []fake.Upstream{{Address: "a:80"}, {Address: "canary:80", NumRequests: 3}},
+			mg:        proxyRoute(withExternalName("proxyroute-a"), withCanary),
+			want:      want{phase: v1alpha1.CanaryPhaseProgressing, weight: 10},
+		},
+		"Healthy": {
+			reason:    "A canary whose fails haven't reached maxFails should progress.",
+			upstreams: []fake.Upstream{{Address: "a:80"}, {Address: "canary:80", Fails: 1}},
+			mg:        proxyRoute(withExternalName("proxyroute-a"), withCanary, withPassive),
+			want:      want{phase: v1alpha1.CanaryPhaseProgressing, weight: 10},
+		},
+		"Unhealthy": {
+			reason:    "A canary whose fails reached maxFails should be rolled back.",
+			upstreams: []fake.Upstream{{Address: "a:80"}, {Address: "canary:80", Fails: 2}},
+			mg:        proxyRoute(withExternalName("proxyroute-a"), withCanary, withPassive),
+			want:      want{phase: v1alpha1.CanaryPhaseRolledBack},
+		},
+		"StableUnhealthy": {
+			reason:    "A canary should progress regardless of the health of the stable group.",
+			upstreams: []fake.Upstream{{Address: "a:80", Fails: 2}, {Address: "canary:80"}},
+			mg:        proxyRoute(withExternalName("proxyroute-a"), withCanary, withPassive),
+			want:      want{phase: v1alpha1.CanaryPhaseProgressing, weight: 10},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, srv := newExternal(t, routeA)
+			srv.SetUpstreams(tc.upstreams...)
+
+			// The canary starts progressing during the first observation,
+			// and may be rolled back from the second.
+			for range 2 {
+				if _, err := e.Observe(context.Background(), tc.mg); err != nil {
+					t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+				}
+			}
+			c := tc.mg.Status.AtProvider.Canary
+			if c == nil {
+				t.Fatalf("\n%s\ne.Observe(...): want canary observation, got none", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want, want{phase: c.Phase, weight: c.Weight}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want canary, +got canary:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+// isUpToDate reports whether the observed Caddy route is semantically equal to
+// the desired one. When it is not it also returns a human-readable diff, in
+// which lines prefixed with "-" are observed and "+" are desired.
+func isUpToDate(desired, observed *caddyclient.ProxyRoute) (bool, string) {
+	diff := cmp.Diff(normalizeRoute(observed), normalizeRoute(desired), cmpopts.EquateEmpty())
+	return diff == "", diff
+}
+
+// normalizeRoute returns a copy of the supplied route in which values that
+// Caddy treats as equivalent are represented identically, so that they do
+// not register as drift. The supplied route is not modified.
+func normalizeRoute(in *caddyclient.ProxyRoute) *caddyclient.ProxyRoute {
+	if in == nil {
+		return nil
+	}
+
+	// Round trip through JSON to get a deep copy that shares no slices or
+	// maps with the input.
+	out := &caddyclient.ProxyRoute{}
+	b, _ := json.Marshal(in)
+	_ = json.Unmarshal(b, out)
+
+	// The @id identifies the route; it is not part of its desired state.
+	out.ID = ""
+
+	for i := range out.Match {
+		normalizeMatchSet(&out.Match[i])
+	}
+	for i := range out.Handle {
+		normalizeHandler(&out.Handle[i])
+	}
+	return out
+}
+
+func normalizeMatchSet(m *caddyclient.MatchSet) {
+	// Host and method matchers are case-insensitive sets, as are IP ranges.
+	m.Host = sortedSet(m.Host, strings.ToLower)
+	m.Method = sortedSet(m.Method, strings.ToUpper)
+	m.Header = canonicalHeaders(m.Header)
+	m.Protocol = strings.ToLower(m.Protocol)
+	for _, r := range []*caddyclient.IPRanges{m.RemoteIP, m.ClientIP} {
+		if r != nil {
+			r.Ranges = sortedSet(r.Ranges, strings.ToLower)
+		}
+	}
+	if len(m.HeaderRegexp) > 0 {
+		re := make(map[string]caddyclient.Regexp, len(m.HeaderRegexp))
+		for k, v := range m.HeaderRegexp {
+			re[http.CanonicalHeaderKey(k)] = v
+		}
+		m.HeaderRegexp = re
+	}
+	for i := range m.Not {
+		normalizeMatchSet(&m.Not[i])
+	}
+}
+
+func normalizeHandler(h *caddyclient.Handler) {
+	if d := h.DynamicUpstreams; d != nil {
+		d.Refresh = normalizeDuration(d.Refresh)
+		d.DialTimeout = normalizeDuration(d.DialTimeout)
+		d.DialFallbackDelay = normalizeDuration(d.DialFallbackDelay)
+		if d.Resolver != nil && len(d.Resolver.Addresses) == 0 {
+			d.Resolver = nil
+		}
+	}
+
+	if lb := h.LoadBalancing; lb != nil {
+		lb.TryDuration = normalizeDuration(lb.TryDuration)
+		lb.TryInterval = normalizeDuration(lb.TryInterval)
+		lb.SelectionPolicy = normalizeSelectionPolicy(lb.SelectionPolicy)
+		for i := range lb.RetryMatch {
+			normalizeMatchSet(&lb.RetryMatch[i])
+		}
+		if reflect.ValueOf(*lb).IsZero() {
+			h.LoadBalancing = nil
+		}
+	}
+
+	if hd := h.Headers; hd != nil {
+		for _, ops := range []*caddyclient.HeaderOps{hd.Request, hd.Response} {
+			if ops == nil {
+				continue
+			}
+			ops.Set = canonicalHeaders(ops.Set)
+			ops.Add = canonicalHeaders(ops.Add)
+			ops.Delete = sortedSet(ops.Delete, http.CanonicalHeaderKey)
+		}
+	}
+
+	if hc := h.HealthChecks; hc != nil {
+		if a := hc.Active; a != nil {
+			a.Interval = normalizeDuration(a.Interval)
+			a.Timeout = normalizeDuration(a.Timeout)
+			a.Headers = canonicalHeaders(a.Headers)
+		}
+		if p := hc.Passive; p != nil {
+			p.FailDuration = normalizeDuration(p.FailDuration)
+			p.UnhealthyLatency = normalizeDuration(p.UnhealthyLatency)
+		}
+	}
+
+	if t := h.Transport; t != nil {
+		t.DialTimeout = normalizeDuration(t.DialTimeout)
+		t.ResponseHeaderTimeout = normalizeDuration(t.ResponseHeaderTimeout)
+		t.ReadTimeout = normalizeDuration(t.ReadTimeout)
+		t.WriteTimeout = normalizeDuration(t.WriteTimeout)
+		if t.Resolver != nil && len(t.Resolver.Addresses) == 0 {
+			t.Resolver = nil
+		}
+		if k := t.KeepAlive; k != nil {
+			k.ProbeInterval = normalizeDuration(k.ProbeInterval)
+			k.IdleTimeout = normalizeDuration(k.IdleTimeout)
+		}
+
+		// A plain HTTP transport is what Caddy uses when none is configured.
+		plain := *t
+		plain.Protocol = ""
+		if (t.Protocol == "" || t.Protocol == "http") && reflect.ValueOf(plain).IsZero() {
+			h.Transport = nil
+		}
+	}
+}
+
+// redactionKey keys the HMAC that secrets are replaced with in diffs. It is
+// random so that the HMACs can't be used to guess the secrets.
+var redactionKey = func() []byte {
+	k := make([]byte, 32)
+	_, _ = rand.Read(k)
+	return k
+}()
+
+// redact returns an HMAC of the supplied secret, which is equal for equal
+// secrets but doesn't reveal them.
+func redact(secret string) string {
+	m := hmac.New(sha256.New, redactionKey)
+	m.Write([]byte(secret))
+	return "redacted:" + hex.EncodeToString(m.Sum(nil))[:16]
+}
+
+// normalizeSelectionPolicy returns the supplied policy, or nil if it's empty.
+// A cookie signing secret is redacted, so that it doesn't appear in diffs.
+func normalizeSelectionPolicy(sp *caddyclient.SelectionPolicy) *caddyclient.SelectionPolicy {
+	if sp == nil {
+		return nil
+	}
+	sp.Field = http.CanonicalHeaderKey(sp.Field)
+	sp.MaxAge = normalizeDuration(sp.MaxAge)
+	if sp.Secret != "" {
+		sp.Secret = redact(sp.Secret)
+	}
+	sp.Fallback = normalizeSelectionPolicy(sp.Fallback)
+	if reflect.ValueOf(*sp).IsZero() {
+		return nil
+	}
+	return sp
+}
+
+// sortedSet returns the supplied values, transformed by fn, sorted and without
+// duplicates.
+func sortedSet(in []string, fn func(string) string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		v = fn(v)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// canonicalHeaders returns the supplied headers keyed by their canonical
+// header names.
+func canonicalHeaders(in map[string][]string) map[string][]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(in))
+	for k, v := range in {
+		k = http.CanonicalHeaderKey(k)
+		out[k] = append(out[k], v...)
+	}
+	return out
+}
+
+// normalizeDuration returns the canonical form of a Caddy duration string, so
+// that e.g. "1m" and "60s", or "1d" and "24h", compare equal. Values that are
+// not durations are returned unchanged.
+func normalizeDuration(d string) string {
+	parsed, err := caddyclient.ParseDuration(d)
+	if err != nil {
+		return d
+	}
+	return parsed.String()
+}
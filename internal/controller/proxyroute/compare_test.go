@@ -0,0 +1,282 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+func TestIsUpToDate(t *testing.T) {
+	// handled returns a route with the supplied handler, that matches
+	// requests for example.com.
+	handled := func(h caddyclient.Handler) *caddyclient.ProxyRoute {
+		h.Handler = "reverse_proxy"
+		if h.Upstreams == nil {
+			h.Upstreams = []caddyclient.Upstream{{Dial: "a:80"}}
+		}
+		return &caddyclient.ProxyRoute{
+			Match:    []caddyclient.MatchSet{{Host: []string{"example.com"}}},
+			Handle:   []caddyclient.Handler{h},
+			Terminal: true,
+		}
+	}
+
+	type args struct {
+		desired  *caddyclient.ProxyRoute
+		observed *caddyclient.ProxyRoute
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"Equal": {
+			reason: "Identical routes should be up to date.",
+			args:   args{desired: handled(caddyclient.Handler{}), observed: handled(caddyclient.Handler{})},
+			want:   true,
+		},
+		"IgnoreID": {
+			reason: "The @id identifies the route, and is not part of its desired state.",
+			args: args{
+				desired:  handled(caddyclient.Handler{}),
+				observed: func() *caddyclient.ProxyRoute { r := handled(caddyclient.Handler{}); r.ID = "proxyroute-a"; return r }(),
+			},
+			want: true,
+		},
+		"DifferentUpstreams": {
+			reason: "Routes that proxy to different upstreams should not be up to date.",
+			args: args{
+				desired:  handled(caddyclient.Handler{Upstreams: []caddyclient.Upstream{{Dial: "a:80"}}}),
+				observed: handled(caddyclient.Handler{Upstreams: []caddyclient.Upstream{{Dial: "b:80"}}}),
+			},
+			want: false,
+		},
+		"EquivalentDurations": {
+			reason: "Durations Caddy treats as equal should be up to date.",
+			args: args{
+				desired:  handled(caddyclient.Handler{HealthChecks: &caddyclient.HealthChecks{Active: &caddyclient.ActiveHealthCheck{Interval: "1m"}}}),
+				observed: handled(caddyclient.Handler{HealthChecks: &caddyclient.HealthChecks{Active: &caddyclient.ActiveHealthCheck{Interval: "60s"}}}),
+			},
+			want: true,
+		},
+		"DifferentDurations": {
+			reason: "Different durations should not be up to date.",
+			args: args{
+				desired:  handled(caddyclient.Handler{Transport: &caddyclient.Transport{Protocol: "http", DialTimeout: "5s"}}),
+				observed: handled(caddyclient.Handler{Transport: &caddyclient.Transport{Protocol: "http", DialTimeout: "10s"}}),
+			},
+			want: false,
+		},
+		"HostCaseAndOrder": {
+			reason: "Host matchers are case-insensitive sets.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Host: []string{"a.example.com", "b.example.com"}}}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Host: []string{"B.example.com", "a.example.com", "a.example.com"}}}},
+			},
+			want: true,
+		},
+		"MethodCase": {
+			reason: "Method matchers are case-insensitive.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Method: []string{"GET"}}}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Method: []string{"get"}}}},
+			},
+			want: true,
+		},
+		"PathCase": {
+			reason: "Path matchers are compared exactly.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Path: []string{"/API"}}}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Path: []string{"/api"}}}},
+			},
+			want: false,
+		},
+		"MatchHeaderCase": {
+			reason: "Header matcher names are case-insensitive.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Header: map[string][]string{"x-env": {"prod"}}}}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Header: map[string][]string{"X-Env": {"prod"}}}}},
+			},
+			want: true,
+		},
+		"HeaderOpsCase": {
+			reason: "Header names of header operations are case-insensitive.",
+			args: args{
+				desired: handled(caddyclient.Handler{Headers: &caddyclient.Headers{Request: &caddyclient.HeaderOps{
+					Set:    map[string][]string{"x-forwarded-proto": {"https"}},
+					Delete: []string{"x-powered-by"},
+				}}}),
+				observed: handled(caddyclient.Handler{Headers: &caddyclient.Headers{Request: &caddyclient.HeaderOps{
+					Set:    map[string][]string{"X-Forwarded-Proto": {"https"}},
+					Delete: []string{"X-Powered-By"},
+				}}}),
+			},
+			want: true,
+		},
+		"HeaderValueCase": {
+			reason: "Header values are compared exactly.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Header: map[string][]string{"X-Env": {"Prod"}}}}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Header: map[string][]string{"X-Env": {"prod"}}}}},
+			},
+			want: false,
+		},
+		"NilAndEmptyCollections": {
+			reason: "Nil and empty slices and maps should be equal.",
+			args: args{
+				desired:  &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{Host: []string{}, Header: map[string][]string{}}}, Handle: []caddyclient.Handler{}},
+				observed: &caddyclient.ProxyRoute{Match: []caddyclient.MatchSet{{}}},
+			},
+			want: true,
+		},
+		"EmptyLoadBalancing": {
+			reason: "An empty load balancing config is what Caddy uses when none is configured.",
+			args: args{
+				desired:  handled(caddyclient.Handler{LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{}}}),
+				observed: handled(caddyclient.Handler{}),
+			},
+			want: true,
+		},
+		"PlainTransport": {
+			reason: "A plain HTTP transport is what Caddy uses when none is configured.",
+			args: args{
+				desired:  handled(caddyclient.Handler{Transport: &caddyclient.Transport{Protocol: "http"}}),
+				observed: handled(caddyclient.Handler{}),
+			},
+			want: true,
+		},
+		"EmptyResolver": {
+			reason: "A resolver without addresses should be equal to no resolver.",
+			args: args{
+				desired:  handled(caddyclient.Handler{DynamicUpstreams: &caddyclient.DynamicUpstreams{Source: "srv", Name: "api", Resolver: &caddyclient.Resolver{}}}),
+				observed: handled(caddyclient.Handler{DynamicUpstreams: &caddyclient.DynamicUpstreams{Source: "srv", Name: "api"}}),
+			},
+			want: true,
+		},
+		"EqualCookieSecrets": {
+			reason: "Routes with the same cookie secret should be up to date.",
+			args: args{
+				desired:  handled(caddyclient.Handler{LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "s3cr3t"}}}),
+				observed: handled(caddyclient.Handler{LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "s3cr3t"}}}),
+			},
+			want: true,
+		},
+		"DifferentCookieSecrets": {
+			reason: "Routes with different cookie secrets should not be up to date.",
+			args: args{
+				desired:  handled(caddyclient.Handler{LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "new-s3cr3t"}}}),
+				observed: handled(caddyclient.Handler{LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "old-s3cr3t"}}}),
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, diff := isUpToDate(tc.args.desired, tc.args.observed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nisUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if got != (diff == "") {
+				t.Errorf("\n%s\nisUpToDate(...): up to date is %t, but diff is %q", tc.reason, got, diff)
+			}
+			for _, secret := range []string{"s3cr3t", "new-s3cr3t", "old-s3cr3t"} {
+				if strings.Contains(diff, `"`+secret+`"`) {
+					t.Errorf("\n%s\nisUpToDate(...): diff reveals secret %q:\n%s\n", tc.reason, secret, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeRoute(t *testing.T) {
+	in := &caddyclient.ProxyRoute{
+		ID:    "proxyroute-a",
+		Match: []caddyclient.MatchSet{{Host: []string{"B.example.com", "a.example.com"}}},
+		Handle: []caddyclient.Handler{{
+			Handler:       "reverse_proxy",
+			LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "s3cr3t"}},
+		}},
+	}
+	want := &caddyclient.ProxyRoute{
+		ID:    "proxyroute-a",
+		Match: []caddyclient.MatchSet{{Host: []string{"B.example.com", "a.example.com"}}},
+		Handle: []caddyclient.Handler{{
+			Handler:       "reverse_proxy",
+			LoadBalancing: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "cookie", Secret: "s3cr3t"}},
+		}},
+	}
+
+	got := normalizeRoute(in)
+	if diff := cmp.Diff(want, in); diff != "" {
+		t.Errorf("normalizeRoute(...): must not modify its input: -want, +got:\n%s\n", diff)
+	}
+	if got.ID != "" {
+		t.Errorf("normalizeRoute(...): want no @id, got %q", got.ID)
+	}
+	if s := got.Handle[0].LoadBalancing.SelectionPolicy.Secret; s == "s3cr3t" || s != redact("s3cr3t") {
+		t.Errorf("normalizeRoute(...): want cookie secret redacted as %q, got %q", redact("s3cr3t"), s)
+	}
+}
+
+func TestNormalizeDuration(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		d      string
+		want   string
+	}{
+		"Empty": {
+			reason: "An unset duration should stay unset.",
+			d:      "",
+			want:   "",
+		},
+		"Seconds": {
+			reason: "A duration in seconds should be canonicalized.",
+			d:      "60s",
+			want:   "1m0s",
+		},
+		"Mixed": {
+			reason: "A duration with several units should be canonicalized.",
+			d:      "1h30m",
+			want:   "1h30m0s",
+		},
+		"Days": {
+			reason: "A duration in Caddy's day unit should be canonicalized, so that it equals the same duration in hours.",
+			d:      "1d",
+			want:   "24h0m0s",
+		},
+		"Invalid": {
+			reason: "A value that is not a duration should be returned unchanged.",
+			d:      "soon",
+			want:   "soon",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, normalizeDuration(tc.d)); diff != "" {
+				t.Errorf("\n%s\nnormalizeDuration(%q): -want, +got:\n%s\n", tc.reason, tc.d, diff)
+			}
+		})
+	}
+}
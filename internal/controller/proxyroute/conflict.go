@@ -0,0 +1,249 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/event"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+// respondingHandlers write a response without passing the request on, so a
+// route that uses one shadows later routes even if it is not terminal.
+var respondingHandlers = map[string]bool{
+	"reverse_proxy":   true,
+	"static_response": true,
+	"file_server":     true,
+	"error":           true,
+}
+
+// observeConflicts sets the Conflict condition of the supplied resource,
+// whose route has the supplied @id, to reflect whether an earlier route among
+// its server's routes overlaps with it. A Warning event names the winning
+// route whenever the resource becomes shadowed by it.
+func (e *external) observeConflicts(ctx context.Context, mg resource.Managed, routeID string, own *caddyclient.ProxyRoute, routes []caddyclient.ProxyRoute) {
+	i := findConflict(routes, routeID, own)
+	if i < 0 {
+		mg.SetConditions(v1alpha1.NoConflict())
+		return
+	}
+
+	c := v1alpha1.Shadowed(e.describeRoute(ctx, mg, &routes[i], i))
+	if prev := mg.GetCondition(v1alpha1.TypeConflict); prev.Status != corev1.ConditionTrue || prev.Message != c.Message {
+		e.recorder.Event(mg, event.Warning(reasonConflictDetected, errors.New(c.Message)))
+	}
+	mg.SetConditions(c)
+}
+
+// describeRoute returns a human-readable description of the supplied route,
+// found at the supplied index of its server's routes, for the Conflict
+// condition of the supplied resource. Routes that belong to a ProxyRoute are
+// described by the ProxyRoute's name. A namespaced resource learns nothing
+// about routes that don't belong to a ProxyRoute in its namespace but their
+// index.
+func (e *external) describeRoute(ctx context.Context, mg resource.Managed, r *caddyclient.ProxyRoute, index int) string {
+	if r.ID == "" {
+		return fmt.Sprintf("unmanaged route at index %d", index)
+	}
+
+	ns := mg.GetNamespace()
+	if ns == "" {
+		cl := &v1alpha1.ProxyRouteList{}
+		if err := e.kube.List(ctx, cl); err == nil {
+			for i := range cl.Items {
+				if meta.GetExternalName(&cl.Items[i]) == r.ID {
+					return fmt.Sprintf("ProxyRoute %s", cl.Items[i].GetName())
+				}
+			}
+		}
+	}
+	nl := &namespacedv1alpha1.ProxyRouteList{}
+	if err := e.kube.List(ctx, nl, client.InNamespace(ns)); err == nil {
+		for i := range nl.Items {
+			if meta.GetExternalName(&nl.Items[i]) == r.ID {
+				return fmt.Sprintf("ProxyRoute %s/%s", nl.Items[i].GetNamespace(), nl.Items[i].GetName())
+			}
+		}
+	}
+	if ns != "" {
+		return fmt.Sprintf("another route at index %d", index)
+	}
+	return fmt.Sprintf("route %q at index %d", r.ID, index)
+}
+
+// findConflict returns the index of the first route that Caddy evaluates
+// before the route with the supplied @id, and that would handle requests the
+// supplied route matches. It returns -1 if there is no such route.
+func findConflict(routes []caddyclient.ProxyRoute, routeID string, own *caddyclient.ProxyRoute) int {
+	for i := range routes {
+		if routes[i].ID == routeID {
+			return -1
+		}
+		if shadows(&routes[i]) && overlaps(&routes[i], own) {
+			return i
+		}
+	}
+	return -1
+}
+
+// shadows reports whether a request the supplied route handles can never
+// reach later routes.
+func shadows(r *caddyclient.ProxyRoute) bool {
+	if r.Terminal {
+		return true
+	}
+	for _, h := range r.Handle {
+		if respondingHandlers[h.Handler] {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether a request could match both of the supplied
+// routes. A route matches a request if any of its match sets does, and a
+// match set matches if all of its matchers do. The check is conservative:
+// matchers it can't reason about are assumed to overlap.
+func overlaps(a, b *caddyclient.ProxyRoute) bool {
+	// A route without match sets matches every request.
+	if len(a.Match) == 0 || len(b.Match) == 0 {
+		return true
+	}
+	for i := range a.Match {
+		for j := range b.Match {
+			if matchSetsOverlap(&a.Match[i], &b.Match[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchSetsOverlap(a, b *caddyclient.MatchSet) bool {
+	return anyOverlap(a.Host, b.Host, hostsOverlap) &&
+		anyOverlap(a.Path, b.Path, pathsOverlap) &&
+		anyOverlap(a.Method, b.Method, strings.EqualFold) &&
+		headersOverlap(a.Header, b.Header)
+}
+
+// anyOverlap reports whether any value of a overlaps with any value of b
+// according to fn. An empty matcher matches every request.
+func anyOverlap(a, b []string, fn func(x, y string) bool) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if fn(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostsOverlap reports whether a request host could match both of the
+// supplied host matchers, in which a "*" label matches any one label.
+func hostsOverlap(a, b string) bool {
+	al := strings.Split(strings.ToLower(a), ".")
+	bl := strings.Split(strings.ToLower(b), ".")
+	if len(al) != len(bl) {
+		return false
+	}
+	for i := range al {
+		if al[i] != bl[i] && al[i] != "*" && bl[i] != "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// pathsOverlap reports whether a request path could match both of the
+// supplied path matchers. Caddy matches paths case-insensitively, and a "*"
+// matches any sequence of characters.
+func pathsOverlap(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	ai, bi := strings.IndexByte(a, '*'), strings.IndexByte(b, '*')
+	switch {
+	case ai < 0 && bi < 0:
+		return a == b
+	case ai < 0:
+		return globMatch(b, a)
+	case bi < 0:
+		return globMatch(a, b)
+	}
+
+	// Both are patterns. Comparing their literal prefixes and suffixes is
+	// exact for the common prefix ("/api/*") and suffix ("*.php") patterns.
+	ap, bp := a[:ai], b[:bi]
+	as, bs := a[strings.LastIndexByte(a, '*')+1:], b[strings.LastIndexByte(b, '*')+1:]
+	return (strings.HasPrefix(ap, bp) || strings.HasPrefix(bp, ap)) &&
+		(strings.HasSuffix(as, bs) || strings.HasSuffix(bs, as))
+}
+
+// globMatch reports whether s matches pattern, in which "*" matches any
+// sequence of characters.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// headersOverlap reports whether a request could match both of the supplied
+// header matchers. Only headers both match on can rule out an overlap.
+func headersOverlap(a, b map[string][]string) bool {
+	bc := canonicalHeaders(b)
+	for k, av := range canonicalHeaders(a) {
+		bv, ok := bc[k]
+		if !ok {
+			continue
+		}
+		if !anyOverlap(av, bv, headerValuesOverlap) {
+			return false
+		}
+	}
+	return true
+}
+
+func headerValuesOverlap(a, b string) bool {
+	if strings.Contains(a, "*") || strings.Contains(b, "*") {
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/test"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+func TestHostsOverlap(t *testing.T) {
+	cases := map[string]struct {
+		a, b string
+		want bool
+	}{
+		"Equal":             {a: "example.com", b: "example.com", want: true},
+		"CaseInsensitive":   {a: "Example.COM", b: "example.com", want: true},
+		"Different":         {a: "example.com", b: "example.org", want: false},
+		"Wildcard":          {a: "*.example.com", b: "api.example.com", want: true},
+		"WildcardBothSides": {a: "*.example.com", b: "api.*.com", want: true},
+		"WildcardOneLabel":  {a: "*.example.com", b: "a.b.example.com", want: false},
+		"WildcardApex":      {a: "*.example.com", b: "example.com", want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, hostsOverlap(tc.a, tc.b)); diff != "" {
+				t.Errorf("hostsOverlap(%q, %q): -want, +got:\n%s\n", tc.a, tc.b, diff)
+			}
+			if diff := cmp.Diff(tc.want, hostsOverlap(tc.b, tc.a)); diff != "" {
+				t.Errorf("hostsOverlap(%q, %q): -want, +got:\n%s\n", tc.b, tc.a, diff)
+			}
+		})
+	}
+}
+
+func TestPathsOverlap(t *testing.T) {
+	cases := map[string]struct {
+		a, b string
+		want bool
+	}{
+		"EqualExact":          {a: "/api", b: "/api", want: true},
+		"DifferentExact":      {a: "/api", b: "/web", want: false},
+		"CaseInsensitive":     {a: "/API", b: "/api", want: true},
+		"CatchAll":            {a: "*", b: "/api", want: true},
+		"PrefixMatchesExact":  {a: "/api/*", b: "/api/users", want: true},
+		"PrefixMissesExact":   {a: "/api/*", b: "/web/users", want: false},
+		"NestedPrefixes":      {a: "/api/*", b: "/api/v1/*", want: true},
+		"DisjointPrefixes":    {a: "/api/*", b: "/web/*", want: false},
+		"SuffixMatchesExact":  {a: "*.php", b: "/index.php", want: true},
+		"SuffixMissesExact":   {a: "*.php", b: "/index.html", want: false},
+		"PrefixAndSuffix":     {a: "/api/*", b: "*.json", want: true},
+		"DisjointSuffixes":    {a: "*.php", b: "*.html", want: false},
+		"InnerWildcardMisses": {a: "/api/*/users", b: "/api/v1/groups", want: false},
+		"InnerWildcardMatch":  {a: "/api/*/users", b: "/api/v1/users", want: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, pathsOverlap(tc.a, tc.b)); diff != "" {
+				t.Errorf("pathsOverlap(%q, %q): -want, +got:\n%s\n", tc.a, tc.b, diff)
+			}
+			if diff := cmp.Diff(tc.want, pathsOverlap(tc.b, tc.a)); diff != "" {
+				t.Errorf("pathsOverlap(%q, %q): -want, +got:\n%s\n", tc.b, tc.a, diff)
+			}
+		})
+	}
+}
+
+func TestFindConflict(t *testing.T) {
+	own := caddyclient.ProxyRoute{
+		ID:       "proxyroute-a",
+		Match:    []caddyclient.MatchSet{{Host: []string{"example.com"}, Path: []string{"/api/*"}}},
+		Handle:   []caddyclient.Handler{{Handler: "reverse_proxy"}},
+		Terminal: true,
+	}
+
+	cases := map[string]struct {
+		reason string
+		routes []caddyclient.ProxyRoute
+		want   int
+	}{
+		"NoEarlierRoutes": {
+			reason: "A route that is evaluated first can't be shadowed.",
+			routes: []caddyclient.ProxyRoute{own, {Handle: []caddyclient.Handler{{Handler: "file_server"}}}},
+			want:   -1,
+		},
+		"CatchAll": {
+			reason: "An earlier route without matchers that responds should shadow the route.",
+			routes: []caddyclient.ProxyRoute{{Handle: []caddyclient.Handler{{Handler: "file_server"}}}, own},
+			want:   0,
+		},
+		"NotTerminal": {
+			reason: "An earlier route that neither is terminal nor responds should not shadow the route.",
+			routes: []caddyclient.ProxyRoute{{Handle: []caddyclient.Handler{{Handler: "headers"}}}, own},
+			want:   -1,
+		},
+		"DifferentHost": {
+			reason: "An earlier route for a different host should not shadow the route.",
+			routes: []caddyclient.ProxyRoute{
+				{Match: []caddyclient.MatchSet{{Host: []string{"example.org"}}}, Terminal: true},
+				own,
+			},
+			want: -1,
+		},
+		"AnyMatchSet": {
+			reason: "An earlier route should shadow the route if any of its match sets overlaps.",
+			routes: []caddyclient.ProxyRoute{
+				{Match: []caddyclient.MatchSet{{Host: []string{"example.org"}}, {Path: []string{"/api/users"}}}, Terminal: true},
+				own,
+			},
+			want: 0,
+		},
+		"DifferentHeader": {
+			reason: "An earlier route that requires a different value of a header should not shadow the route.",
+			routes: []caddyclient.ProxyRoute{
+				{Match: []caddyclient.MatchSet{{Header: map[string][]string{"X-Env": {"staging"}}}}, Terminal: true},
+				{
+					ID:       "proxyroute-a",
+					Match:    []caddyclient.MatchSet{{Header: map[string][]string{"x-env": {"production"}}}},
+					Terminal: true,
+				},
+			},
+			want: -1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			i := len(tc.routes) - 1
+			if diff := cmp.Diff(tc.want, findConflict(tc.routes, "proxyroute-a", &tc.routes[i])); diff != "" {
+				t.Errorf("\n%s\nfindConflict(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDescribeRoute(t *testing.T) {
+	withName := func(mg resource.Managed, name string) resource.Managed {
+		meta.SetExternalName(mg, name)
+		return mg
+	}
+	cluster := withName(&v1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Name: "platform"}}, "proxyroute-platform")
+	teamA := withName(&namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"}}, "proxyroute-web")
+	teamB := withName(&namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "api"}}, "proxyroute-api")
+
+	// kube lists the ProxyRoutes above, honoring the namespace to list.
+	kube := &test.MockClient{MockList: func(_ context.Context, l client.ObjectList, opts ...client.ListOption) error {
+		o := &client.ListOptions{}
+		o.ApplyOptions(opts)
+		switch l := l.(type) {
+		case *v1alpha1.ProxyRouteList:
+			l.Items = []v1alpha1.ProxyRoute{*cluster.(*v1alpha1.ProxyRoute)}
+		case *namespacedv1alpha1.ProxyRouteList:
+			for _, mg := range []resource.Managed{teamA, teamB} {
+				if o.Namespace == "" || o.Namespace == mg.GetNamespace() {
+					l.Items = append(l.Items, *mg.(*namespacedv1alpha1.ProxyRoute))
+				}
+			}
+		}
+		return nil
+	}}
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		route  caddyclient.ProxyRoute
+		want   string
+	}{
+		"Unmanaged": {
+			reason: "A route without an @id should be described by its index.",
+			mg:     &v1alpha1.ProxyRoute{},
+			want:   "unmanaged route at index 3",
+		},
+		"ClusterSeesCluster": {
+			reason: "A cluster scoped ProxyRoute should see the name of a cluster scoped winner.",
+			mg:     &v1alpha1.ProxyRoute{},
+			route:  caddyclient.ProxyRoute{ID: "proxyroute-platform"},
+			want:   "ProxyRoute platform",
+		},
+		"ClusterSeesNamespaced": {
+			reason: "A cluster scoped ProxyRoute should see the name of a namespaced winner.",
+			mg:     &v1alpha1.ProxyRoute{},
+			route:  caddyclient.ProxyRoute{ID: "proxyroute-api"},
+			want:   "ProxyRoute team-b/api",
+		},
+		"SameNamespace": {
+			reason: "A namespaced ProxyRoute should see the name of a winner in its own namespace.",
+			mg:     &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			route:  caddyclient.ProxyRoute{ID: "proxyroute-web"},
+			want:   "ProxyRoute team-a/web",
+		},
+		"OtherNamespace": {
+			reason: "A namespaced ProxyRoute should not see the name of a winner in another namespace.",
+			mg:     &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			route:  caddyclient.ProxyRoute{ID: "proxyroute-api"},
+			want:   "another route at index 3",
+		},
+		"ClusterSeesUnknown": {
+			reason: "A cluster scoped ProxyRoute should see the @id of a winner that belongs to no ProxyRoute.",
+			mg:     &v1alpha1.ProxyRoute{},
+			route:  caddyclient.ProxyRoute{ID: "other"},
+			want:   `route "other" at index 3`,
+		},
+		"NamespacedSeesNoUnknown": {
+			reason: "A namespaced ProxyRoute should not see the @id of a winner that belongs to no ProxyRoute.",
+			mg:     &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			route:  caddyclient.ProxyRoute{ID: "other"},
+			want:   "another route at index 3",
+		},
+		"NamespacedSeesNoCluster": {
+			reason: "A namespaced ProxyRoute should not see the name of a cluster scoped winner.",
+			mg:     &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}},
+			route:  caddyclient.ProxyRoute{ID: "proxyroute-platform"},
+			want:   "another route at index 3",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{kube: kube}
+			if diff := cmp.Diff(tc.want, e.describeRoute(context.Background(), tc.mg, &tc.route, 3)); diff != "" {
+				t.Errorf("\n%s\ne.describeRoute(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-caddy/apis/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+const (
+	// routeIDIndexKey indexes ProxyRoutes by their external name, i.e. the
+	// @id of their route.
+	routeIDIndexKey = "metadata.annotations.externalName"
+
+	// maxNamespacedPriority is the highest priority a namespaced ProxyRoute's
+	// route ranks by, whatever priority it specifies.
+	maxNamespacedPriority = 0
+)
+
+// How specific a path matcher is. More specific paths are evaluated first.
+const (
+	pathCatchAll = iota
+	pathPattern
+	pathExact
+)
+
+// A rank determines the order of a route among its server's routes. Routes
+// that rank higher are evaluated first.
+type rank struct {
+	priority int
+
+	// The remaining fields are only set when ordering by specificity. They
+	// describe the least specific of the route's match sets.
+	host       bool
+	path       int
+	pathLength int
+}
+
+// outranks reports whether a should be evaluated before b.
+func (a rank) outranks(b rank) bool {
+	switch {
+	case a.priority != b.priority:
+		return a.priority > b.priority
+	case a.host != b.host:
+		return a.host
+	case a.path != b.path:
+		return a.path > b.path
+	default:
+		return a.pathLength > b.pathLength
+	}
+}
+
+// order returns the order in which the route with the supplied @id and
+// priority should be evaluated relative to its server's other routes. The
+// priorities of other routes are read from the ProxyRoutes that manage them.
+func (e *external) order(ctx context.Context, routeID string, priority int) caddyclient.Order {
+	priorities := map[string]int{routeID: priority}
+	bySpecificity := e.ordering == apisv1alpha1.RouteOrderingSpecificity
+
+	rankOf := func(r *caddyclient.ProxyRoute) rank {
+		rk := rank{}
+		if bySpecificity {
+			rk = specificity(r)
+		}
+		if r.ID == "" {
+			return rk
+		}
+		pr, ok := priorities[r.ID]
+		if !ok {
+			pr = e.priority(ctx, r.ID)
+			priorities[r.ID] = pr
+		}
+		rk.priority = pr
+		return rk
+	}
+	return func(a, b *caddyclient.ProxyRoute) bool {
+		return rankOf(a).outranks(rankOf(b))
+	}
+}
+
+// priority returns the priority of the route with the supplied @id, read from
+// the ProxyRoute whose external name it is. Routes not managed by a ProxyRoute
+// have priority 0.
+func (e *external) priority(ctx context.Context, routeID string) int {
+	cl := &v1alpha1.ProxyRouteList{}
+	if err := e.kube.List(ctx, cl, client.MatchingFields{routeIDIndexKey: routeID}); err != nil {
+		e.logger.Info("Failed to list ProxyRoutes", "route", routeID, "error", err)
+	}
+	if len(cl.Items) > 0 {
+		return priorityOf(&cl.Items[0])
+	}
+	nl := &namespacedv1alpha1.ProxyRouteList{}
+	if err := e.kube.List(ctx, nl, client.MatchingFields{routeIDIndexKey: routeID}); err != nil {
+		e.logger.Info("Failed to list ProxyRoutes", "route", routeID, "error", err)
+	}
+	if len(nl.Items) > 0 {
+		return priorityOf(&nl.Items[0])
+	}
+	return 0
+}
+
+// priorityOf returns the priority the route of the supplied ProxyRoute ranks
+// by. A namespaced ProxyRoute's priority is capped at maxNamespacedPriority,
+// so that a tenant can't outrank cluster scoped ProxyRoutes, or routes not
+// managed by a ProxyRoute.
+func priorityOf(mg resource.Managed) int {
+	p, _, err := proxyRouteOf(mg)
+	if err != nil {
+		return 0
+	}
+	pr := ptr.Deref(p.Priority, 0)
+	if _, ok := mg.(*namespacedv1alpha1.ProxyRoute); ok {
+		return min(pr, maxNamespacedPriority)
+	}
+	return pr
+}
+
+// routeIDKeys returns the index keys of the supplied ProxyRoute, i.e. its
+// external name, which is the @id of its route.
+func routeIDKeys(obj client.Object) []string {
+	if id := meta.GetExternalName(obj); id != "" {
+		return []string{id}
+	}
+	return nil
+}
+
+// specificity ranks the supplied route by the least specific of its match
+// sets, because that is the broadest set of requests it matches. A route
+// without match sets matches every request.
+func specificity(r *caddyclient.ProxyRoute) rank {
+	if len(r.Match) == 0 {
+		return rank{}
+	}
+	least := matchSetSpecificity(&r.Match[0])
+	for i := range r.Match[1:] {
+		if rk := matchSetSpecificity(&r.Match[i+1]); least.outranks(rk) {
+			least = rk
+		}
+	}
+	return least
+}
+
+func matchSetSpecificity(m *caddyclient.MatchSet) rank {
+	rk := rank{host: len(m.Host) > 0}
+	for i, p := range m.Path {
+		class, length := pathSpecificity(p)
+		if i == 0 || class < rk.path || (class == rk.path && length < rk.pathLength) {
+			rk.path, rk.pathLength = class, length
+		}
+	}
+	return rk
+}
+
+// pathSpecificity returns how specific the supplied path matcher is, and the
+// length of its literal prefix.
+func pathSpecificity(p string) (int, int) {
+	switch i := strings.IndexByte(p, '*'); {
+	case p == "*" || p == "/*":
+		return pathCatchAll, 0
+	case i < 0:
+		return pathExact, len(p)
+	default:
+		return pathPattern, i
+	}
+}
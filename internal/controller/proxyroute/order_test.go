@@ -0,0 +1,264 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/test"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-caddy/apis/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+func TestOrder(t *testing.T) {
+	// matching returns a route with the supplied @id and match sets, each
+	// of which matches a host if it is non-empty, and a path.
+	matching := func(id string, sets ...[2]string) caddyclient.ProxyRoute {
+		r := caddyclient.ProxyRoute{ID: id}
+		for _, s := range sets {
+			m := caddyclient.MatchSet{Path: []string{s[1]}}
+			if s[0] != "" {
+				m.Host = []string{s[0]}
+			}
+			r.Match = append(r.Match, m)
+		}
+		return r
+	}
+
+	// kube lists a cluster scoped ProxyRoute whose route has priority 5, and
+	// a namespaced one whose route has priority 10, by their route IDs.
+	high := v1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Name: "high"}}
+	high.Spec.ForProvider.Priority = ptr.To(5)
+	meta.SetExternalName(&high, "proxyroute-high")
+	tenant := namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "tenant"}}
+	tenant.Spec.ForProvider.Priority = ptr.To(10)
+	meta.SetExternalName(&tenant, "proxyroute-tenant")
+	kube := &test.MockClient{MockList: func(_ context.Context, l client.ObjectList, opts ...client.ListOption) error {
+		o := &client.ListOptions{}
+		o.ApplyOptions(opts)
+		if o.FieldSelector == nil {
+			return errors.New("ProxyRoutes must be listed by route ID")
+		}
+		switch l := l.(type) {
+		case *v1alpha1.ProxyRouteList:
+			if o.FieldSelector.Matches(fields.Set{routeIDIndexKey: "proxyroute-high"}) {
+				l.Items = []v1alpha1.ProxyRoute{high}
+			}
+		case *namespacedv1alpha1.ProxyRouteList:
+			if o.FieldSelector.Matches(fields.Set{routeIDIndexKey: "proxyroute-tenant"}) {
+				l.Items = []namespacedv1alpha1.ProxyRoute{tenant}
+			}
+		}
+		return nil
+	}}
+
+	type args struct {
+		ordering string
+		priority int
+		a, b     caddyclient.ProxyRoute
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"EqualPriority": {
+			reason: "Routes of equal priority should not be ordered when ordering by priority.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingPriority,
+				a:        matching("proxyroute-own", [2]string{"example.com", "/api"}),
+				b:        matching("unmanaged", [2]string{"", "/*"}),
+			},
+			want: false,
+		},
+		"OwnPriority": {
+			reason: "The route being ordered should rank by the priority it is supplied with.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingPriority,
+				priority: 1,
+				a:        matching("proxyroute-own"),
+				b:        matching("unmanaged"),
+			},
+			want: true,
+		},
+		"OtherPriority": {
+			reason: "Other routes should rank by the priority of the ProxyRoute that manages them.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingPriority,
+				priority: 4,
+				a:        matching("proxyroute-high"),
+				b:        matching("proxyroute-own"),
+			},
+			want: true,
+		},
+		"NamespacedPriorityCapped": {
+			reason: "A namespaced ProxyRoute's priority should not outrank routes of priority 0.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingPriority,
+				a:        matching("proxyroute-tenant"),
+				b:        matching("unmanaged"),
+			},
+			want: false,
+		},
+		"ClusterOutranksNamespaced": {
+			reason: "A cluster scoped ProxyRoute's priority should outrank a namespaced ProxyRoute that specifies a higher one.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingPriority,
+				a:        matching("proxyroute-high"),
+				b:        matching("proxyroute-tenant"),
+			},
+			want: true,
+		},
+		"PriorityBeforeSpecificity": {
+			reason: "A higher priority should outrank a more specific route.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-high", [2]string{"", "/*"}),
+				b:        matching("proxyroute-own", [2]string{"example.com", "/api"}),
+			},
+			want: true,
+		},
+		"HostBeforeHostless": {
+			reason: "A route that matches a host should outrank one that doesn't.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"example.com", "/*"}),
+				b:        matching("unmanaged", [2]string{"", "/api"}),
+			},
+			want: true,
+		},
+		"ExactBeforePattern": {
+			reason: "An exact path should outrank a path pattern.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"", "/a"}),
+				b:        matching("unmanaged", [2]string{"", "/api/v1/*"}),
+			},
+			want: true,
+		},
+		"LongerPrefixFirst": {
+			reason: "A path pattern with a longer literal prefix should outrank a shorter one.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"", "/api/v1/*"}),
+				b:        matching("unmanaged", [2]string{"", "/api/*"}),
+			},
+			want: true,
+		},
+		"PatternBeforeCatchAll": {
+			reason: "A path pattern should outrank a catch-all.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"", "/api/*"}),
+				b:        matching("unmanaged"),
+			},
+			want: true,
+		},
+		"LeastSpecificMatchSet": {
+			reason: "A route should rank by its least specific match set.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"example.com", "/api"}, [2]string{"", "/*"}),
+				b:        matching("unmanaged", [2]string{"", "/api/*"}),
+			},
+			want: false,
+		},
+		"EqualSpecificity": {
+			reason: "Routes of equal priority and specificity should not be ordered.",
+			args: args{
+				ordering: apisv1alpha1.RouteOrderingSpecificity,
+				a:        matching("proxyroute-own", [2]string{"example.com", "/api/*"}),
+				b:        matching("unmanaged", [2]string{"example.org", "/web/*"}),
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{kube: kube, ordering: tc.args.ordering, logger: logging.NewNopLogger()}
+			order := e.order(context.Background(), "proxyroute-own", tc.args.priority)
+			if diff := cmp.Diff(tc.want, order(&tc.args.a, &tc.args.b)); diff != "" {
+				t.Errorf("\n%s\norder(a, b): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPriorityOf(t *testing.T) {
+	cluster := func(pr *int) resource.Managed {
+		cr := &v1alpha1.ProxyRoute{}
+		cr.Spec.ForProvider.Priority = pr
+		return cr
+	}
+	namespaced := func(pr *int) resource.Managed {
+		cr := &namespacedv1alpha1.ProxyRoute{}
+		cr.Spec.ForProvider.Priority = pr
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   int
+	}{
+		"Default": {
+			reason: "A ProxyRoute without a priority should have priority 0.",
+			mg:     cluster(nil),
+			want:   0,
+		},
+		"Cluster": {
+			reason: "A cluster scoped ProxyRoute should have the priority it specifies.",
+			mg:     cluster(ptr.To(10)),
+			want:   10,
+		},
+		"NamespacedCapped": {
+			reason: "A namespaced ProxyRoute's priority should be capped at 0.",
+			mg:     namespaced(ptr.To(10)),
+			want:   0,
+		},
+		"NamespacedNegative": {
+			reason: "A namespaced ProxyRoute should have a negative priority it specifies.",
+			mg:     namespaced(ptr.To(-5)),
+			want:   -5,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, priorityOf(tc.mg)); diff != "" {
+				t.Errorf("\n%s\npriorityOf(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
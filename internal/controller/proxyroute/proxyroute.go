@@ -18,10 +18,21 @@ package proxyroute
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
@@ -34,229 +45,817 @@ import (
 	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
 
 	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-caddy/apis/v1alpha1"
 	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
 )
 
 const (
-	errNotProxyRoute = "managed resource is not a ProxyRoute custom resource"
-	errTrackPCUsage  = "cannot track ProviderConfig usage"
-	errGetPC         = "cannot get ProviderConfig"
-	errGetCreds      = "cannot get credentials"
-	errNewClient     = "cannot create new Caddy client"
-	errCreateRoute   = "cannot create proxy route"
-	errUpdateRoute   = "cannot update proxy route"
-	errDeleteRoute   = "cannot delete proxy route"
-	errGetRoute      = "cannot get proxy route"
+	errNotProxyRoute         = "managed resource is not a ProxyRoute custom resource"
+	errTrackPCUsage          = "cannot track ProviderConfig usage"
+	errGetPC                 = "cannot get ProviderConfig"
+	errNoPCRef               = "no providerConfigRef provided"
+	errGetCreds              = "cannot get credentials"
+	errNoCreds               = "no credentials found"
+	errGetTLSSecret          = "cannot get admin API TLS secret"
+	errNewClient             = "cannot create new Caddy client"
+	errNoEndpoint            = "no Caddy admin API endpoint: set the ProviderConfig's endpoint, or a cluster scoped ProxyRoute's caddyEndpoint"
+	errCreateRoute           = "cannot create proxy route"
+	errUpdateRoute           = "cannot update proxy route"
+	errDeleteRoute           = "cannot delete proxy route"
+	errGetRoute              = "cannot get proxy route"
+	errAdoptRoute            = "cannot adopt proxy route identified by legacy external name"
+	errOrderRoute            = "cannot order proxy route"
+	errIndexServices         = "cannot index ProxyRoutes by service"
+	errIndexRouteIDs         = "cannot index ProxyRoutes by route ID"
+	errGetCookieSecret       = "cannot get load balancing cookie secret"
+	errInvalidCircuitBreaker = "circuit breaker config must be a JSON object"
+
+	errFmtUnsupportedPCKind  = "unsupported provider config kind %q"
+	errFmtNamespacedPCSource = "a namespaced ProviderConfig cannot load credentials from source %q; use Secret or None"
+	errFmtRefNamespace       = "%s must be in the ProxyRoute's namespace %q"
+	errFmtRefNoNamespace     = "a cluster scoped ProxyRoute must specify the %s's namespace"
+	errFmtNoSecretKey        = "secret has no key %q"
+	errFmtInvalidRegexp      = "invalid %s pattern"
+	errFmtInvalidDuration    = "invalid %s duration"
+	errFmtForeignRoute       = "external name %q does not identify this ProxyRoute's route: a namespaced ProxyRoute may only manage the route it created, %q"
+
+	reasonDriftDetected    event.Reason = "DriftDetected"
+	reasonConflictDetected event.Reason = "ConflictDetected"
+	// reasonPassiveHealthChecksIneffective indicates passive health checks
+	// are configured without a fail duration, so Caddy never counts failed
+	// requests.
+	reasonPassiveHealthChecksIneffective event.Reason = "PassiveHealthChecksIneffective"
+
+	// reasonCredentialsUnavailable indicates the credentials or TLS material
+	// the ProviderConfig specifies could not be loaded.
+	reasonCredentialsUnavailable xpv1.ConditionReason = "CredentialsUnavailable"
+	// reasonUnauthorized indicates the Caddy admin API rejected the
+	// credentials, or requires credentials that were not supplied.
+	reasonUnauthorized xpv1.ConditionReason = "Unauthorized"
+	// reasonServerNotFound indicates the Caddy server the route belongs to
+	// is not configured.
+	reasonServerNotFound xpv1.ConditionReason = "ServerNotFound"
+	// reasonInvalidConfig indicates Caddy rejected the route.
+	reasonInvalidConfig xpv1.ConditionReason = "InvalidConfig"
+	// reasonConcurrentModification indicates Caddy's config kept changing
+	// while the route was being written.
+	reasonConcurrentModification xpv1.ConditionReason = "ConcurrentModification"
+	// reasonUnreachable indicates the Caddy admin API could not be reached or
+	// failed to handle the request.
+	reasonUnreachable xpv1.ConditionReason = "Unreachable"
+	// reasonForeignRoute indicates a namespaced ProxyRoute's external name
+	// identifies a route it didn't create, which it may not manage.
+	reasonForeignRoute xpv1.ConditionReason = "ForeignRoute"
+	// reasonInsufficientHealthyUpstreams indicates fewer upstreams are
+	// healthy than the ProxyRoute requires to be Ready.
+	reasonInsufficientHealthyUpstreams xpv1.ConditionReason = "InsufficientHealthyUpstreams"
+
+	// defaultServerName is the Caddy server routes are added to unless a
+	// ProxyRoute specifies one.
+	defaultServerName = "srv0"
+
+	// keyCACert is the key of the CA bundle in the admin API TLS secret.
+	keyCACert = "ca.crt"
+
+	// routeIDPrefix prefixes the @id a ProxyRoute's Caddy route is tagged
+	// with. The remainder of the @id is the ProxyRoute's UID.
+	routeIDPrefix = "proxyroute-"
 )
 
 // SetupGated adds a controller that reconciles ProxyRoute managed resources with safe-start support.
-func SetupGated(mgr ctrl.Manager, o controller.Options) error {
-	return Setup(mgr, o)
+func SetupGated(mgr ctrl.Manager, o controller.Options, ro caddyclient.RequestOptions) error {
+	return Setup(mgr, o, ro)
+}
+
+// Setup adds controllers that reconcile cluster scoped and namespaced
+// ProxyRoute managed resources. The supplied request options apply unless a
+// ProviderConfig overrides them.
+func Setup(mgr ctrl.Manager, o controller.Options, ro caddyclient.RequestOptions) error {
+	if err := setupNamespaced(mgr, o, ro); err != nil {
+		return err
+	}
+	return setupCluster(mgr, o, ro)
+}
+
+func setupCluster(mgr ctrl.Manager, o controller.Options, ro caddyclient.RequestOptions) error {
+	newList := func() resource.ManagedList { return &v1alpha1.ProxyRouteList{} }
+	return setup(mgr, o, v1alpha1.ProxyRouteGroupKind, v1alpha1.ProxyRouteGroupVersionKind, &v1alpha1.ProxyRoute{}, newList, &connector{
+		kube:     mgr.GetClient(),
+		usage:    newClusterUsageTracker(mgr.GetClient()),
+		requests: ro,
+		logger:   o.Logger,
+	})
+}
+
+func setupNamespaced(mgr ctrl.Manager, o controller.Options, ro caddyclient.RequestOptions) error {
+	newList := func() resource.ManagedList { return &namespacedv1alpha1.ProxyRouteList{} }
+	return setup(mgr, o, namespacedv1alpha1.ProxyRouteGroupKind, namespacedv1alpha1.ProxyRouteGroupVersionKind, &namespacedv1alpha1.ProxyRoute{}, newList, &connector{
+		kube:     mgr.GetClient(),
+		usage:    newNamespacedUsageTracker(mgr.GetClient()),
+		requests: ro,
+		logger:   o.Logger,
+	})
 }
 
-// Setup adds a controller that reconciles ProxyRoute managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	name := managed.ControllerName(v1alpha1.ProxyRouteGroupKind)
+// setup adds a controller that reconciles the supplied kind of ProxyRoute
+// using the supplied connector. ProxyRoutes that discover upstreams from a
+// Service are also reconciled when its EndpointSlices change.
+func setup(mgr ctrl.Manager, o controller.Options, gk string, gvk schema.GroupVersionKind, obj client.Object, newList func() resource.ManagedList, c *connector) error {
+	name := managed.ControllerName(gk)
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, serviceIndexKey, serviceKeys); err != nil {
+		return errors.Wrap(err, errIndexServices)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, routeIDIndexKey, routeIDKeys); err != nil {
+		return errors.Wrap(err, errIndexRouteIDs)
+	}
+	c.recorder = event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
 
 	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(v1alpha1.ProxyRouteGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube:   mgr.GetClient(),
-			logger: o.Logger,
-		}),
+		resource.ManagedKind(gvk),
+		managed.WithExternalConnecter(c),
+		// Route IDs are derived from the resource's UID at creation time rather
+		// than from its name, so the external name is not initialized.
+		managed.WithInitializers(),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		managed.WithPollIntervalHook(canaryPollInterval),
+		managed.WithRecorder(c.recorder))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
-		WithEventFilter(resource.DesiredStateChanged()).
-		For(&v1alpha1.ProxyRoute{}).
+		For(obj, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&discoveryv1.EndpointSlice{}, enqueueForEndpointSlice(mgr.GetClient(), newList, o.Logger)).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method is called.
 type connector struct {
-	kube   client.Client
-	logger logging.Logger
+	kube     client.Client
+	usage    resource.Tracker
+	requests caddyclient.RequestOptions
+	logger   logging.Logger
+	recorder event.Recorder
 }
 
 // Connect typically produces an ExternalClient by:
-// 1. Getting the managed resource's ProviderConfig.
-// 2. Getting the credentials specified by the ProviderConfig.
-// 3. Using the credentials to form a client.
-// Note: For Caddy, we use the endpoint specified directly in the ProxyRoute spec.
+// 1. Tracking that the managed resource is using a ProviderConfig.
+// 2. Getting the managed resource's ProviderConfig.
+// 3. Getting the credentials specified by the ProviderConfig.
+// 4. Using the endpoint, TLS settings and credentials to form a client.
+// A cluster scoped ProxyRoute may override the ProviderConfig's endpoint.
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.ProxyRoute)
-	if !ok {
-		return nil, errors.New(errNotProxyRoute)
+	if _, _, err := proxyRouteOf(mg); err != nil {
+		return nil, err
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc, err := c.providerConfig(ctx, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	// Only cluster scoped ProxyRoutes may override the endpoint. Otherwise a
+	// tenant could send the credentials of a ProviderConfig they're allowed to
+	// use to an endpoint of their choosing.
+	endpoint := pc.Endpoint
+	if cr, ok := mg.(*v1alpha1.ProxyRoute); ok && cr.Spec.ForProvider.CaddyEndpoint != nil {
+		endpoint = *cr.Spec.ForProvider.CaddyEndpoint
+	}
+	if endpoint == "" {
+		err := errors.New(errNoEndpoint)
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return nil, err
+	}
+
+	opts := []caddyclient.Option{caddyclient.WithRequestOptions(c.requestOptions(*pc))}
+	if t := pc.TLS; t != nil {
+		o, err := c.tlsOptions(ctx, t)
+		if err != nil {
+			mg.SetConditions(unavailable(reasonCredentialsUnavailable, err))
+			return nil, err
+		}
+		opts = append(opts, caddyclient.WithTLS(o))
+	}
+
+	creds, err := c.credentials(ctx, pc.Credentials)
+	if err != nil {
+		mg.SetConditions(unavailable(reasonCredentialsUnavailable, err))
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+	if creds != nil {
+		opts = append(opts, caddyclient.WithCredentials(*creds))
+	}
+
+	cl, err := caddyclient.NewClient(endpoint, opts...)
+	if err != nil {
+		// TLS material that can't be parsed is as unusable as credentials
+		// that can't be loaded.
+		if caddyclient.IsInvalidTLSConfig(err) {
+			mg.SetConditions(unavailable(reasonCredentialsUnavailable, err))
+		}
+		return nil, errors.Wrap(err, errNewClient)
 	}
 
 	return &external{
-		client: caddyclient.NewClient(cr.Spec.ForProvider.CaddyEndpoint),
-		logger: c.logger,
+		kube:     c.kube,
+		client:   cl,
+		ordering: ptr.Deref(pc.RouteOrdering, apisv1alpha1.RouteOrderingPriority),
+		logger:   c.logger,
+		recorder: c.recorder,
 	}, nil
 }
 
+// providerConfig returns the spec of the ProviderConfig or
+// ClusterProviderConfig the supplied ProxyRoute references. A cluster scoped
+// ProxyRoute always references a ClusterProviderConfig.
+func (c *connector) providerConfig(ctx context.Context, mg resource.Managed) (*apisv1alpha1.ProviderConfigSpec, error) {
+	if cr, ok := mg.(*v1alpha1.ProxyRoute); ok {
+		ref := cr.GetProviderConfigReference()
+		if ref == nil {
+			return nil, errors.New(errNoPCRef)
+		}
+		pc := &apisv1alpha1.ClusterProviderConfig{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+			return nil, err
+		}
+		return &pc.Spec, nil
+	}
+
+	cr, ok := mg.(*namespacedv1alpha1.ProxyRoute)
+	if !ok {
+		return nil, errors.New(errNotProxyRoute)
+	}
+	ref := cr.GetProviderConfigReference()
+	if ref == nil {
+		return nil, errors.New(errNoPCRef)
+	}
+
+	switch ref.Kind {
+	case apisv1alpha1.ClusterProviderConfigKind:
+		pc := &apisv1alpha1.ClusterProviderConfig{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+			return nil, err
+		}
+		return &pc.Spec, nil
+	case apisv1alpha1.ProviderConfigKind:
+		pc := &apisv1alpha1.ProviderConfig{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: ref.Name}, pc); err != nil {
+			return nil, err
+		}
+		// A namespaced ProviderConfig may only use Secrets in its own
+		// namespace. Other credential sources read the provider's own
+		// environment or filesystem, and would send them to an endpoint
+		// chosen by the tenant.
+		switch pc.Spec.Credentials.Source {
+		case xpv1.CredentialsSourceNone, xpv1.CredentialsSourceSecret:
+		default:
+			return nil, errors.Errorf(errFmtNamespacedPCSource, pc.Spec.Credentials.Source)
+		}
+		spec := pc.Spec.DeepCopy()
+		if spec.Credentials.SecretRef != nil {
+			spec.Credentials.SecretRef.Namespace = pc.GetNamespace()
+		}
+		if spec.TLS != nil && spec.TLS.SecretRef != nil {
+			spec.TLS.SecretRef.Namespace = pc.GetNamespace()
+		}
+		return spec, nil
+	default:
+		return nil, errors.Errorf(errFmtUnsupportedPCKind, ref.Kind)
+	}
+}
+
+// requestOptions returns the connector's request options, overridden by any
+// the ProviderConfig specifies.
+func (c *connector) requestOptions(pc apisv1alpha1.ProviderConfigSpec) caddyclient.RequestOptions {
+	o := c.requests
+	if pc.Timeout != nil {
+		o.Timeout = pc.Timeout.Duration
+	}
+	if pc.MaxRetries != nil {
+		o.MaxRetries = *pc.MaxRetries
+	}
+	if pc.RetryBackoff != nil {
+		o.RetryBackoff = pc.RetryBackoff.Duration
+	}
+	return o
+}
+
+// credentials loads the admin API credentials specified by a ProviderConfig.
+// It returns nil if the ProviderConfig specifies no credentials.
+func (c *connector) credentials(ctx context.Context, pc apisv1alpha1.ProviderCredentials) (*caddyclient.Credentials, error) {
+	if pc.Source == xpv1.CredentialsSourceNone {
+		return nil, nil
+	}
+	data, err := resource.CommonCredentialExtractor(ctx, pc.Source, c.kube, pc.CommonCredentialSelectors)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New(errNoCreds)
+	}
+	creds, err := caddyclient.ParseCredentials(data)
+	if err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// tlsOptions builds the options used to connect to the admin API over TLS,
+// loading the client certificate and CA bundle from the referenced Secret.
+func (c *connector) tlsOptions(ctx context.Context, t *apisv1alpha1.AdminTLS) (caddyclient.TLSOptions, error) {
+	o := caddyclient.TLSOptions{
+		ServerName:         ptr.Deref(t.ServerName, ""),
+		InsecureSkipVerify: ptr.Deref(t.InsecureSkipVerify, false),
+	}
+	if t.SecretRef == nil {
+		return o, nil
+	}
+
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: t.SecretRef.Namespace, Name: t.SecretRef.Name}, s); err != nil {
+		return caddyclient.TLSOptions{}, errors.Wrap(err, errGetTLSSecret)
+	}
+	o.CAPEM = s.Data[keyCACert]
+	o.CertPEM = s.Data[corev1.TLSCertKey]
+	o.KeyPEM = s.Data[corev1.TLSPrivateKeyKey]
+	return o, nil
+}
+
+// newNamespacedUsageTracker returns a tracker that records a namespaced
+// ProxyRoute's use of the ProviderConfig or ClusterProviderConfig it
+// references as a ProviderConfigUsage in the ProxyRoute's namespace.
+func newNamespacedUsageTracker(kube client.Client) resource.Tracker {
+	t := resource.NewProviderConfigUsageTracker(kube, &apisv1alpha1.ProviderConfigUsage{})
+	return resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error {
+		cr, ok := mg.(*namespacedv1alpha1.ProxyRoute)
+		if !ok {
+			return errors.New(errNotProxyRoute)
+		}
+		return t.Track(ctx, cr)
+	})
+}
+
+// newClusterUsageTracker returns a tracker that records a cluster scoped
+// ProxyRoute's use of the ClusterProviderConfig it references as a
+// ClusterProviderConfigUsage.
+func newClusterUsageTracker(kube client.Client) resource.Tracker {
+	t := resource.NewProviderConfigUsageTracker(kube, &apisv1alpha1.ClusterProviderConfigUsage{})
+	return resource.TrackerFn(func(ctx context.Context, mg resource.Managed) error {
+		cr, ok := mg.(*v1alpha1.ProxyRoute)
+		if !ok {
+			return errors.New(errNotProxyRoute)
+		}
+		return t.Track(ctx, clusterScoped{ProxyRoute: cr})
+	})
+}
+
+// clusterScoped adapts a cluster scoped ProxyRoute, which references its
+// ClusterProviderConfig by name only, to the typed provider config reference
+// the usage tracker expects.
+type clusterScoped struct {
+	*v1alpha1.ProxyRoute
+}
+
+// GetProviderConfigReference returns a reference to the ClusterProviderConfig
+// the ProxyRoute references.
+func (c clusterScoped) GetProviderConfigReference() *xpv1.ProviderConfigReference {
+	ref := c.ProxyRoute.GetProviderConfigReference()
+	if ref == nil {
+		return nil
+	}
+	return &xpv1.ProviderConfigReference{Kind: apisv1alpha1.ClusterProviderConfigKind, Name: ref.Name}
+}
+
+// SetProviderConfigReference is a no-op; the reference is read-only.
+func (c clusterScoped) SetProviderConfigReference(_ *xpv1.ProviderConfigReference) {}
+
+// GetWriteConnectionSecretToReference returns nil; ProxyRoutes have no
+// connection details.
+func (c clusterScoped) GetWriteConnectionSecretToReference() *xpv1.LocalSecretReference {
+	return nil
+}
+
+// SetWriteConnectionSecretToReference is a no-op; ProxyRoutes have no
+// connection details.
+func (c clusterScoped) SetWriteConnectionSecretToReference(_ *xpv1.LocalSecretReference) {}
+
 // An external observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
-	client *caddyclient.Client
-	logger logging.Logger
+	kube     client.Client
+	client   *caddyclient.Client
+	ordering string
+	logger   logging.Logger
+	recorder event.Recorder
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	cr, ok := mg.(*v1alpha1.ProxyRoute)
-	if !ok {
-		return managed.ExternalObservation{}, errors.New(errNotProxyRoute)
+	p, o, err := proxyRouteOf(mg)
+	if err != nil {
+		return managed.ExternalObservation{}, err
 	}
 
-	// Get the external name (route ID) from the annotation
-	routeID := meta.GetExternalName(cr)
+	serverName := ptr.Deref(p.ServerName, defaultServerName)
+
+	// Get the external name (route ID) from the annotation. If it is not set
+	// yet we look for the route under the ID Create would have tagged it with,
+	// in case we created it but failed to record its external name.
+	lateInitialized := false
+	routeID := meta.GetExternalName(mg)
 	if routeID == "" {
-		return managed.ExternalObservation{
-			ResourceExists: false,
-		}, nil
+		routeID = routeIDFor(mg)
+		lateInitialized = true
 	}
 
-	serverName := "srv0"
-	if cr.Spec.ForProvider.ServerName != nil {
-		serverName = *cr.Spec.ForProvider.ServerName
+	// A namespaced ProxyRoute may only manage the route it created, or a
+	// route it adopts by its legacy external name, which is then tagged with
+	// the ID it would have created it with. Otherwise a tenant could take
+	// over another tenant's route by setting its external name.
+	if _, ok := mg.(*namespacedv1alpha1.ProxyRoute); ok && routeID != routeIDFor(mg) && !caddyclient.IsLegacyRouteID(routeID) {
+		err := errors.Errorf(errFmtForeignRoute, routeID, routeIDFor(mg))
+		mg.SetConditions(unavailable(reasonForeignRoute, err))
+		return managed.ExternalObservation{}, err
 	}
 
-	route, err := e.client.GetProxyRoute(ctx, serverName, routeID)
+	// Routes created by earlier releases are identified by an external name
+	// derived from their match conditions. Tag such a route with an @id and
+	// switch the external name over to it.
+	if caddyclient.IsLegacyRouteID(routeID) {
+		id, err := e.client.AdoptLegacyRoute(ctx, serverName, routeID, routeIDFor(mg))
+		if err != nil {
+			setErrorCondition(mg, err)
+			return managed.ExternalObservation{}, errors.Wrap(err, errAdoptRoute)
+		}
+		if id == "" {
+			return managed.ExternalObservation{
+				ResourceExists: false,
+			}, nil
+		}
+		routeID = id
+		lateInitialized = true
+	}
+
+	route, err := e.client.GetProxyRoute(ctx, routeID)
 	if err != nil {
 		// If the route is not found, treat it as non-existent
-		if strings.Contains(err.Error(), "not found") {
+		if caddyclient.IsNotFound(err) {
 			return managed.ExternalObservation{
 				ResourceExists: false,
 			}, nil
 		}
 		// For other errors, return them
+		setErrorCondition(mg, err)
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetRoute)
 	}
 
+	if lateInitialized {
+		meta.SetExternalName(mg, routeID)
+	}
+
 	// Update the status with observed values
-	cr.Status.AtProvider.RouteID = routeID
+	o.RouteID = routeID
 
-	// Get upstream status
-	upstreams, err := e.client.GetUpstreamStatus(ctx)
+	deps, err := e.dependencies(ctx, mg, p)
 	if err != nil {
-		// Don't fail if we can't get upstream status
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return managed.ExternalObservation{}, err
+	}
+	desired := desiredRoute(p, o, deps)
+
+	// Get upstream status. Caddy only reports the status of static upstreams.
+	if p.DynamicUpstreams != nil {
+		observeDynamicUpstreams(o)
+		mg.SetConditions(v1alpha1.DynamicUpstreamsHealth())
+	} else if upstreams, err := e.client.GetUpstreamStatus(ctx); err != nil {
+		// Don't fail if we can't get upstream status. A progressive canary
+		// doesn't progress without it.
 		e.logger.Info("Failed to get upstream status", "error", err)
 	} else {
-		cr.Status.AtProvider.UpstreamStatuses = convertUpstreamStatuses(upstreams)
+		observeUpstreams(p, desired.Handle[0].Upstreams, o, upstreams)
+		if _, known := maxFails(p); known || o.TotalUpstreams == 0 {
+			mg.SetConditions(v1alpha1.UpstreamsHealthy(o.HealthyUpstreams, o.TotalUpstreams))
+		} else {
+			mg.SetConditions(v1alpha1.UnknownUpstreamsHealth())
+		}
+
+		if ev := progressCanary(p, o, canaryHealthy(p, deps.upstreams.canary, upstreams), metav1.Now()); ev != nil {
+			e.recorder.Event(mg, *ev)
+			desired = desiredRoute(p, o, deps)
+		}
 	}
 
 	// Determine if the resource is up to date
-	upToDate := isUpToDate(cr, route)
+	upToDate, diff := isUpToDate(desired, route)
+	if !upToDate {
+		e.logger.Debug("Caddy route has drifted from desired state", "route", routeID, "diff", diff)
+		e.recorder.Event(mg, event.Normal(reasonDriftDetected, "Caddy route differs from desired state (-observed +desired):\n"+diff))
+	}
+
+	// Check how the route relates to the server's other routes. Neither check
+	// fails the observation if the routes can't be read.
+	if routes, err := e.client.ListProxyRoutes(ctx, serverName); err != nil {
+		e.logger.Info("Failed to list routes", "error", err)
+	} else {
+		e.observeConflicts(ctx, mg, routeID, route, routes)
 
-	cr.Status.SetConditions(xpv1.Available())
+		current, ordered := caddyclient.RouteIndex(routes, routeID, e.order(ctx, routeID, priorityOf(mg)))
+		if current >= 0 {
+			o.Position = ptr.To(current)
+		}
+		if current != ordered {
+			e.logger.Debug("Caddy route is out of order", "route", routeID, "position", current, "desired", ordered)
+			upToDate = false
+			diff += fmt.Sprintf("route is at position %d of server %q's routes, but should be at position %d\n", current, serverName, ordered)
+		}
+	}
+
+	// Optionally hold off on becoming Ready until enough upstreams are
+	// healthy. If Caddy's upstream status could not be read we use what we
+	// last observed.
+	if required := p.MinHealthyUpstreams; required != nil && o.HealthyUpstreams < *required {
+		mg.SetConditions(unavailable(reasonInsufficientHealthyUpstreams,
+			errors.Errorf("%d of the required %d upstreams are healthy", o.HealthyUpstreams, *required)))
+	} else {
+		mg.SetConditions(xpv1.Available())
+	}
 
 	return managed.ExternalObservation{
-		ResourceExists:   true,
-		ResourceUpToDate: upToDate,
+		ResourceExists:          true,
+		ResourceUpToDate:        upToDate,
+		ResourceLateInitialized: lateInitialized,
+		Diff:                    diff,
 	}, nil
 }
 
 func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
-	cr, ok := mg.(*v1alpha1.ProxyRoute)
-	if !ok {
-		return managed.ExternalCreation{}, errors.New(errNotProxyRoute)
+	p, o, err := proxyRouteOf(mg)
+	if err != nil {
+		return managed.ExternalCreation{}, err
 	}
 
-	cr.Status.SetConditions(xpv1.Creating())
+	mg.SetConditions(xpv1.Creating())
 
-	serverName := "srv0"
-	if cr.Spec.ForProvider.ServerName != nil {
-		serverName = *cr.Spec.ForProvider.ServerName
+	if err := validate(p); err != nil {
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return managed.ExternalCreation{}, err
 	}
+	e.warnIneffectiveHealthChecks(mg, p)
 
-	route := convertToProxyRoute(cr)
+	serverName := ptr.Deref(p.ServerName, defaultServerName)
 
-	routeID, err := e.client.CreateProxyRoute(ctx, serverName, route)
+	routeID := routeIDFor(mg)
+	deps, err := e.dependencies(ctx, mg, p)
 	if err != nil {
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return managed.ExternalCreation{}, err
+	}
+	route := desiredRoute(p, o, deps)
+
+	if err := e.client.CreateProxyRoute(ctx, serverName, routeID, route, e.order(ctx, routeID, priorityOf(mg))); err != nil {
+		setErrorCondition(mg, err)
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRoute)
 	}
 
 	// Set the external name annotation
-	meta.SetExternalName(cr, routeID)
+	meta.SetExternalName(mg, routeID)
 
 	return managed.ExternalCreation{}, nil
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	cr, ok := mg.(*v1alpha1.ProxyRoute)
-	if !ok {
-		return managed.ExternalUpdate{}, errors.New(errNotProxyRoute)
+	p, o, err := proxyRouteOf(mg)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
 	}
 
-	serverName := "srv0"
-	if cr.Spec.ForProvider.ServerName != nil {
-		serverName = *cr.Spec.ForProvider.ServerName
+	if err := validate(p); err != nil {
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return managed.ExternalUpdate{}, err
 	}
+	e.warnIneffectiveHealthChecks(mg, p)
 
-	routeID := meta.GetExternalName(cr)
-	route := convertToProxyRoute(cr)
+	routeID := meta.GetExternalName(mg)
+	deps, err := e.dependencies(ctx, mg, p)
+	if err != nil {
+		mg.SetConditions(unavailable(reasonInvalidConfig, err))
+		return managed.ExternalUpdate{}, err
+	}
+	route := desiredRoute(p, o, deps)
 
-	if err := e.client.UpdateProxyRoute(ctx, serverName, routeID, route); err != nil {
+	if err := e.client.UpdateProxyRoute(ctx, routeID, route); err != nil {
+		setErrorCondition(mg, err)
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRoute)
 	}
 
+	// Updating a route keeps its position, so move it if its priority, or
+	// with specificity ordering its match conditions, changed such that it
+	// is out of order.
+	serverName := ptr.Deref(p.ServerName, defaultServerName)
+	routes, err := e.client.ListProxyRoutes(ctx, serverName)
+	if err != nil {
+		setErrorCondition(mg, err)
+		return managed.ExternalUpdate{}, errors.Wrap(err, errOrderRoute)
+	}
+	order := e.order(ctx, routeID, priorityOf(mg))
+	if current, ordered := caddyclient.RouteIndex(routes, routeID, order); current != ordered {
+		if _, err := e.client.OrderProxyRoute(ctx, serverName, routeID, order); err != nil {
+			setErrorCondition(mg, err)
+			return managed.ExternalUpdate{}, errors.Wrap(err, errOrderRoute)
+		}
+	}
+
 	return managed.ExternalUpdate{}, nil
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) (managed.ExternalDelete, error) {
-	cr, ok := mg.(*v1alpha1.ProxyRoute)
-	if !ok {
-		return managed.ExternalDelete{}, errors.New(errNotProxyRoute)
+	if _, _, err := proxyRouteOf(mg); err != nil {
+		return managed.ExternalDelete{}, err
 	}
 
-	cr.Status.SetConditions(xpv1.Deleting())
-
-	serverName := "srv0"
-	if cr.Spec.ForProvider.ServerName != nil {
-		serverName = *cr.Spec.ForProvider.ServerName
-	}
+	mg.SetConditions(xpv1.Deleting())
 
-	routeID := meta.GetExternalName(cr)
+	routeID := meta.GetExternalName(mg)
 	if routeID == "" {
 		// Nothing to delete
 		return managed.ExternalDelete{}, nil
 	}
 
-	if err := e.client.DeleteProxyRoute(ctx, serverName, routeID); err != nil {
+	if err := e.client.DeleteProxyRoute(ctx, routeID); err != nil {
 		return managed.ExternalDelete{}, errors.Wrap(err, errDeleteRoute)
 	}
 
 	return managed.ExternalDelete{}, nil
 }
 
-// Disconnect is called when the controller is shutting down.
-func (e *external) Disconnect(ctx context.Context) error {
-	// Nothing to disconnect for HTTP client
+// Disconnect is called after each reconcile. Each reconcile connects with a
+// new client, so its idle connections to the admin API are closed rather than
+// left open until they time out.
+func (e *external) Disconnect(_ context.Context) error {
+	e.client.CloseIdleConnections()
 	return nil
 }
 
+// warnIneffectiveHealthChecks records a warning event if the supplied
+// parameters configure passive health checks that Caddy won't act on.
+func (e *external) warnIneffectiveHealthChecks(mg resource.Managed, p *v1alpha1.ProxyRouteParameters) {
+	if hc := p.HealthChecks; hc != nil && hc.Passive != nil && hc.Passive.FailDuration == nil {
+		e.recorder.Event(mg, event.Warning(reasonPassiveHealthChecksIneffective,
+			errors.New("passive health checks are configured without failDuration, so Caddy never counts failed requests and won't mark upstreams unhealthy")))
+	}
+}
+
+// unavailable returns a Ready condition that reports the resource unavailable
+// for the supplied reason.
+func unavailable(reason xpv1.ConditionReason, err error) xpv1.Condition {
+	c := xpv1.Unavailable()
+	c.Reason = reason
+	return c.WithMessage(err.Error())
+}
+
+// setErrorCondition sets a Ready condition whose reason describes why a
+// request to the Caddy admin API failed. Errors the client doesn't categorize
+// are left to the managed reconciler to report.
+func setErrorCondition(mg resource.Managed, err error) {
+	var reason xpv1.ConditionReason
+	switch {
+	case caddyclient.IsUnauthorized(err):
+		reason = reasonUnauthorized
+	case caddyclient.IsServerNotFound(err):
+		reason = reasonServerNotFound
+	case caddyclient.IsInvalidConfig(err):
+		reason = reasonInvalidConfig
+	case caddyclient.IsConflict(err):
+		reason = reasonConcurrentModification
+	case caddyclient.IsUnavailable(err):
+		reason = reasonUnreachable
+	default:
+		return
+	}
+	mg.SetConditions(unavailable(reason, err))
+}
+
+// namespaceOf returns the namespace of an object of the supplied kind that
+// the supplied ProxyRoute references. A namespaced ProxyRoute may only
+// reference objects in its own namespace, which is the default. A cluster
+// scoped ProxyRoute must specify the namespace.
+func namespaceOf(mg resource.Managed, ns *string, kind string) (string, error) {
+	n := ptr.Deref(ns, mg.GetNamespace())
+	switch {
+	case mg.GetNamespace() != "" && n != mg.GetNamespace():
+		return "", errors.Errorf(errFmtRefNamespace, kind, mg.GetNamespace())
+	case n == "":
+		return "", errors.Errorf(errFmtRefNoNamespace, kind)
+	}
+	return n, nil
+}
+
+// routeIDFor returns the @id of the Caddy route managed by the supplied
+// resource. It is derived from the resource's UID, which unlike its match
+// conditions is unique and never changes.
+func routeIDFor(mg resource.Managed) string {
+	return routeIDPrefix + string(mg.GetUID())
+}
+
+// proxyRouteOf returns the parameters and observation of the supplied managed
+// resource, which may be a cluster scoped or a namespaced ProxyRoute.
+func proxyRouteOf(mg resource.Managed) (*v1alpha1.ProxyRouteParameters, *v1alpha1.ProxyRouteObservation, error) {
+	switch cr := mg.(type) {
+	case *v1alpha1.ProxyRoute:
+		return &cr.Spec.ForProvider, &cr.Status.AtProvider, nil
+	case *namespacedv1alpha1.ProxyRoute:
+		return &cr.Spec.ForProvider, &cr.Status.AtProvider, nil
+	default:
+		return nil, nil, errors.New(errNotProxyRoute)
+	}
+}
+
+// dependencies are what the desired state of a ProxyRoute's Caddy route
+// depends on besides its parameters, read from Kubernetes.
+type dependencies struct {
+	upstreams    upstreamGroups
+	cookieSecret string
+}
+
+// dependencies reads the dependencies of the supplied ProxyRoute.
+func (e *external) dependencies(ctx context.Context, mg resource.Managed, p *v1alpha1.ProxyRouteParameters) (dependencies, error) {
+	g, err := e.upstreamGroups(ctx, mg, p)
+	if err != nil {
+		return dependencies{}, err
+	}
+	d := dependencies{upstreams: g}
+
+	if lb := p.LoadBalancing; lb != nil && lb.Cookie != nil && lb.Cookie.SecretRef != nil {
+		ref := lb.Cookie.SecretRef
+		ns, err := namespaceOf(mg, ref.Namespace, "secret")
+		if err != nil {
+			return dependencies{}, errors.Wrap(err, errGetCookieSecret)
+		}
+		sec := &corev1.Secret{}
+		if err := e.kube.Get(ctx, types.NamespacedName{Namespace: ns, Name: ref.Name}, sec); err != nil {
+			return dependencies{}, errors.Wrap(err, errGetCookieSecret)
+		}
+		v, ok := sec.Data[ref.Key]
+		if !ok {
+			return dependencies{}, errors.Wrap(errors.Errorf(errFmtNoSecretKey, ref.Key), errGetCookieSecret)
+		}
+		d.cookieSecret = string(v)
+	}
+	return d, nil
+}
+
+// desiredRoute returns the Caddy route the supplied ProxyRoute should
+// configure, given its dependencies. Weights are only configured when the
+// route uses the weighted_round_robin policy, which it always does when it
+// has a canary group.
+func desiredRoute(p *v1alpha1.ProxyRouteParameters, o *v1alpha1.ProxyRouteObservation, d dependencies) *caddyclient.ProxyRoute {
+	route := convertToProxyRoute(p)
+	h := &route.Handle[0]
+
+	if lb := h.LoadBalancing; lb != nil && lb.SelectionPolicy != nil {
+		lb.SelectionPolicy.Secret = d.cookieSecret
+	}
+
+	weighted := p.Canary != nil
+	if lb := p.LoadBalancing; lb != nil && ptr.Deref(lb.Policy, "") == policyWeightedRoundRobin {
+		weighted = true
+	}
+	if !weighted {
+		for _, u := range append(d.upstreams.stable, d.upstreams.canary...) {
+			h.Upstreams = append(h.Upstreams, u.Upstream)
+		}
+		return route
+	}
+
+	var weights []int
+	h.Upstreams, weights = splitTraffic(d.upstreams, canaryWeight(p, o))
+	if h.LoadBalancing == nil {
+		h.LoadBalancing = &caddyclient.LoadBalancing{}
+	}
+	if h.LoadBalancing.SelectionPolicy == nil {
+		h.LoadBalancing.SelectionPolicy = &caddyclient.SelectionPolicy{}
+	}
+	h.LoadBalancing.SelectionPolicy.Policy = policyWeightedRoundRobin
+	h.LoadBalancing.SelectionPolicy.Weights = weights
+	return route
+}
+
 // convertToProxyRoute converts the CRD spec to the Caddy client format.
 //
 //nolint:gocyclo // Conversion function with linear complexity
-func convertToProxyRoute(cr *v1alpha1.ProxyRoute) *caddyclient.ProxyRoute {
+func convertToProxyRoute(p *v1alpha1.ProxyRouteParameters) *caddyclient.ProxyRoute {
 	route := &caddyclient.ProxyRoute{
 		Terminal: true,
 	}
 
 	// Convert match conditions (Caddy expects array of matcher sets)
-	if cr.Spec.ForProvider.Match != nil {
-		route.Match = []caddyclient.MatchSet{
-			{
-				Host:   cr.Spec.ForProvider.Match.Host,
-				Path:   cr.Spec.ForProvider.Match.Path,
-				Method: cr.Spec.ForProvider.Match.Method,
-				Header: cr.Spec.ForProvider.Match.Headers,
-			},
-		}
+	matchSets := matchSetsOf(p)
+	for i := range matchSets {
+		route.Match = append(route.Match, convertMatchSet(&matchSets[i]))
 	}
 
 	// Create the reverse_proxy handler
@@ -264,89 +863,108 @@ func convertToProxyRoute(cr *v1alpha1.ProxyRoute) *caddyclient.ProxyRoute {
 		Handler: "reverse_proxy",
 	}
 
-	// Convert upstreams
-	for _, upstream := range cr.Spec.ForProvider.Upstreams {
-		u := caddyclient.Upstream{
-			Dial: upstream.Dial,
+	// Static upstreams are added by desiredRoute, which also discovers them
+	// and splits traffic between them.
+
+	// Convert dynamic upstreams
+	if d := p.DynamicUpstreams; d != nil {
+		handler.DynamicUpstreams = &caddyclient.DynamicUpstreams{
+			Source:            strings.ToLower(d.Source),
+			Name:              d.Name,
+			Refresh:           ptr.Deref(d.Refresh, ""),
+			DialTimeout:       ptr.Deref(d.DialTimeout, ""),
+			DialFallbackDelay: ptr.Deref(d.DialFallbackDelay, ""),
 		}
-		if upstream.MaxRequests != nil {
-			u.MaxRequests = *upstream.MaxRequests
+		if d.Port != nil {
+			handler.DynamicUpstreams.Port = strconv.Itoa(*d.Port)
+		}
+		if len(d.Resolvers) > 0 {
+			handler.DynamicUpstreams.Resolver = &caddyclient.Resolver{Addresses: d.Resolvers}
 		}
-		handler.Upstreams = append(handler.Upstreams, u)
 	}
 
 	// Convert load balancing
-	if cr.Spec.ForProvider.LoadBalancing != nil {
-		handler.LoadBalancing = &caddyclient.LoadBalancing{}
-		if cr.Spec.ForProvider.LoadBalancing.Policy != nil {
-			handler.LoadBalancing.SelectionPolicy = &caddyclient.SelectionPolicy{
-				Policy: *cr.Spec.ForProvider.LoadBalancing.Policy,
-			}
-		}
-		if cr.Spec.ForProvider.LoadBalancing.TryDuration != nil {
-			handler.LoadBalancing.TryDuration = *cr.Spec.ForProvider.LoadBalancing.TryDuration
-		}
-		if cr.Spec.ForProvider.LoadBalancing.TryInterval != nil {
-			handler.LoadBalancing.TryInterval = *cr.Spec.ForProvider.LoadBalancing.TryInterval
-		}
+	if p.LoadBalancing != nil {
+		handler.LoadBalancing = convertLoadBalancing(p.LoadBalancing)
 	}
 
 	// Convert headers
-	if cr.Spec.ForProvider.Headers != nil {
+	if p.Headers != nil {
 		handler.Headers = &caddyclient.Headers{}
-		if cr.Spec.ForProvider.Headers.Request != nil {
+		if p.Headers.Request != nil {
 			handler.Headers.Request = &caddyclient.HeaderOps{
-				Set:    cr.Spec.ForProvider.Headers.Request.Set,
-				Add:    cr.Spec.ForProvider.Headers.Request.Add,
-				Delete: cr.Spec.ForProvider.Headers.Request.Delete,
+				Set:    p.Headers.Request.Set,
+				Add:    p.Headers.Request.Add,
+				Delete: p.Headers.Request.Delete,
 			}
 		}
-		if cr.Spec.ForProvider.Headers.Response != nil {
+		if p.Headers.Response != nil {
 			handler.Headers.Response = &caddyclient.HeaderOps{
-				Set:    cr.Spec.ForProvider.Headers.Response.Set,
-				Add:    cr.Spec.ForProvider.Headers.Response.Add,
-				Delete: cr.Spec.ForProvider.Headers.Response.Delete,
+				Set:    p.Headers.Response.Set,
+				Add:    p.Headers.Response.Add,
+				Delete: p.Headers.Response.Delete,
 			}
 		}
 	}
 
 	// Convert health checks
-	if cr.Spec.ForProvider.HealthChecks != nil {
+	if p.HealthChecks != nil {
 		handler.HealthChecks = &caddyclient.HealthChecks{}
-		if cr.Spec.ForProvider.HealthChecks.Active != nil {
-			handler.HealthChecks.Active = &caddyclient.ActiveHealthCheck{}
-			if cr.Spec.ForProvider.HealthChecks.Active.Path != nil {
-				handler.HealthChecks.Active.Path = *cr.Spec.ForProvider.HealthChecks.Active.Path
-			}
-			if cr.Spec.ForProvider.HealthChecks.Active.Interval != nil {
-				handler.HealthChecks.Active.Interval = *cr.Spec.ForProvider.HealthChecks.Active.Interval
-			}
-			if cr.Spec.ForProvider.HealthChecks.Active.Timeout != nil {
-				handler.HealthChecks.Active.Timeout = *cr.Spec.ForProvider.HealthChecks.Active.Timeout
+		if a := p.HealthChecks.Active; a != nil {
+			handler.HealthChecks.Active = &caddyclient.ActiveHealthCheck{
+				Path:            ptr.Deref(a.Path, ""),
+				URI:             ptr.Deref(a.URI, ""),
+				Port:            ptr.Deref(a.Port, 0),
+				Headers:         a.Headers,
+				FollowRedirects: ptr.Deref(a.FollowRedirects, false),
+				Interval:        ptr.Deref(a.Interval, ""),
+				Timeout:         ptr.Deref(a.Timeout, ""),
+				Passes:          ptr.Deref(a.Passes, 0),
+				Fails:           ptr.Deref(a.Fails, 0),
+				MaxSize:         ptr.Deref(a.MaxSize, 0),
+				ExpectStatus:    ptr.Deref(a.ExpectStatus, 0),
+				ExpectBody:      ptr.Deref(a.ExpectBody, ""),
 			}
 		}
-		if cr.Spec.ForProvider.HealthChecks.Passive != nil {
-			handler.HealthChecks.Passive = &caddyclient.PassiveHealthCheck{}
-			if cr.Spec.ForProvider.HealthChecks.Passive.MaxFails != nil {
-				handler.HealthChecks.Passive.MaxFails = *cr.Spec.ForProvider.HealthChecks.Passive.MaxFails
+		if ps := p.HealthChecks.Passive; ps != nil {
+			handler.HealthChecks.Passive = &caddyclient.PassiveHealthCheck{
+				FailDuration:          ptr.Deref(ps.FailDuration, ""),
+				MaxFails:              ptr.Deref(ps.MaxFails, 0),
+				UnhealthyStatus:       ps.UnhealthyStatus,
+				UnhealthyLatency:      ptr.Deref(ps.UnhealthyLatency, ""),
+				UnhealthyRequestCount: ptr.Deref(ps.UnhealthyRequestCount, 0),
 			}
-			if cr.Spec.ForProvider.HealthChecks.Passive.UnhealthyLatency != nil {
-				handler.HealthChecks.Passive.UnhealthyLatency = *cr.Spec.ForProvider.HealthChecks.Passive.UnhealthyLatency
+		}
+		if cb := p.HealthChecks.CircuitBreaker; cb != nil {
+			cfg := map[string]any{}
+			if cb.Config != nil && len(cb.Config.Raw) > 0 {
+				// The config is validated to be an object by validate.
+				// Anything else, e.g. null, is ignored when observing.
+				if err := json.Unmarshal(cb.Config.Raw, &cfg); err != nil || cfg == nil {
+					cfg = map[string]any{}
+				}
 			}
+			cfg["type"] = cb.Type
+			handler.CircuitBreaker = cfg
 		}
 	}
 
+	// Convert transport tuning
+	if p.Transport != nil {
+		handler.Transport = convertTransport(p.Transport)
+	}
+
 	// Convert TLS
-	if cr.Spec.ForProvider.TLS != nil && cr.Spec.ForProvider.TLS.Enabled != nil && *cr.Spec.ForProvider.TLS.Enabled {
-		handler.Transport = &caddyclient.Transport{
-			Protocol: "http",
-			TLS:      &caddyclient.TLSConfig{},
+	if p.TLS != nil && p.TLS.Enabled != nil && *p.TLS.Enabled {
+		if handler.Transport == nil {
+			handler.Transport = &caddyclient.Transport{Protocol: "http"}
 		}
-		if cr.Spec.ForProvider.TLS.ServerName != nil {
-			handler.Transport.TLS.ServerName = *cr.Spec.ForProvider.TLS.ServerName
+		handler.Transport.TLS = &caddyclient.TLSConfig{}
+		if p.TLS.ServerName != nil {
+			handler.Transport.TLS.ServerName = *p.TLS.ServerName
 		}
-		if cr.Spec.ForProvider.TLS.InsecureSkipVerify != nil {
-			handler.Transport.TLS.InsecureSkipVerify = *cr.Spec.ForProvider.TLS.InsecureSkipVerify
+		if p.TLS.InsecureSkipVerify != nil {
+			handler.Transport.TLS.InsecureSkipVerify = *p.TLS.InsecureSkipVerify
 		}
 	}
 
@@ -355,23 +973,273 @@ func convertToProxyRoute(cr *v1alpha1.ProxyRoute) *caddyclient.ProxyRoute {
 	return route
 }
 
-// isUpToDate checks if the Caddy route matches the desired state.
-func isUpToDate(cr *v1alpha1.ProxyRoute, route *caddyclient.ProxyRoute) bool {
-	// For simplicity, we'll consider the resource always needs an update
-	// In production, you'd implement a detailed comparison
-	// This encourages periodic reconciliation which is safer for external systems
-	return false
+// convertLoadBalancing converts the CRD load balancing configuration to the
+// Caddy client format. The cookie policy's secret is set by desiredRoute.
+func convertLoadBalancing(lb *v1alpha1.LoadBalancing) *caddyclient.LoadBalancing {
+	out := &caddyclient.LoadBalancing{
+		Retries:     ptr.Deref(lb.Retries, 0),
+		TryDuration: ptr.Deref(lb.TryDuration, ""),
+		TryInterval: ptr.Deref(lb.TryInterval, ""),
+	}
+	for i := range lb.RetryMatch {
+		out.RetryMatch = append(out.RetryMatch, convertMatchSet(&lb.RetryMatch[i]))
+	}
+	if lb.Policy == nil {
+		return out
+	}
+
+	sp := &caddyclient.SelectionPolicy{Policy: *lb.Policy}
+	if h := lb.Header; h != nil {
+		sp.Field = h.Field
+	}
+	if q := lb.Query; q != nil {
+		sp.Key = q.Key
+	}
+	if c := lb.Cookie; c != nil {
+		sp.Name = ptr.Deref(c.Name, "")
+		sp.MaxAge = ptr.Deref(c.MaxAge, "")
+	}
+	if lb.Fallback != nil {
+		sp.Fallback = &caddyclient.SelectionPolicy{Policy: *lb.Fallback}
+	}
+	out.SelectionPolicy = sp
+	return out
+}
+
+// convertTransport converts the CRD HTTP transport tuning to the Caddy client
+// format.
+func convertTransport(t *v1alpha1.HTTPTransport) *caddyclient.Transport {
+	out := &caddyclient.Transport{
+		Protocol:              "http",
+		DialTimeout:           ptr.Deref(t.DialTimeout, ""),
+		ResponseHeaderTimeout: ptr.Deref(t.ResponseHeaderTimeout, ""),
+		ReadTimeout:           ptr.Deref(t.ReadTimeout, ""),
+		WriteTimeout:          ptr.Deref(t.WriteTimeout, ""),
+		Versions:              t.Versions,
+		MaxConnsPerHost:       ptr.Deref(t.MaxConnsPerHost, 0),
+		ReadBufferSize:        ptr.Deref(t.ReadBufferSize, 0),
+		WriteBufferSize:       ptr.Deref(t.WriteBufferSize, 0),
+		ProxyProtocol:         ptr.Deref(t.ProxyProtocol, ""),
+	}
+	if len(t.Resolvers) > 0 {
+		out.Resolver = &caddyclient.Resolver{Addresses: t.Resolvers}
+	}
+	if k := t.KeepAlive; k != nil {
+		out.KeepAlive = &caddyclient.KeepAlive{
+			Enabled:             k.Enabled,
+			ProbeInterval:       ptr.Deref(k.ProbeInterval, ""),
+			MaxIdleConns:        ptr.Deref(k.MaxIdleConns, 0),
+			MaxIdleConnsPerHost: ptr.Deref(k.MaxIdleConnsPerHost, 0),
+			IdleTimeout:         ptr.Deref(k.IdleTimeout, ""),
+		}
+	}
+	return out
+}
+
+// matchSetsOf returns the sets of match conditions of the supplied
+// parameters, which specify either a single set or a list of them.
+func matchSetsOf(p *v1alpha1.ProxyRouteParameters) []v1alpha1.RouteMatch {
+	if p.Match != nil {
+		return []v1alpha1.RouteMatch{*p.Match}
+	}
+	return p.MatchSets
+}
+
+// validate returns an error if the supplied parameters are invalid in ways
+// the CRD schema can't express.
+func validate(p *v1alpha1.ProxyRouteParameters) error {
+	if err := validateMatchSets(p); err != nil {
+		return err
+	}
+	if err := validateDynamicUpstreams(p); err != nil {
+		return err
+	}
+	if err := validateLoadBalancing(p); err != nil {
+		return err
+	}
+	if err := validateHealthChecks(p); err != nil {
+		return err
+	}
+	return validateTransport(p)
+}
+
+// validateDynamicUpstreams returns an error if a duration in the supplied
+// parameters' dynamic upstreams is invalid.
+func validateDynamicUpstreams(p *v1alpha1.ProxyRouteParameters) error {
+	d := p.DynamicUpstreams
+	if d == nil {
+		return nil
+	}
+	return validateDurations([]duration{
+		{"dynamicUpstreams.refresh", d.Refresh},
+		{"dynamicUpstreams.dialTimeout", d.DialTimeout},
+		{"dynamicUpstreams.dialFallbackDelay", d.DialFallbackDelay},
+	})
+}
+
+// validateLoadBalancing returns an error if a duration in the supplied
+// parameters' load balancing config is invalid.
+func validateLoadBalancing(p *v1alpha1.ProxyRouteParameters) error {
+	lb := p.LoadBalancing
+	if lb == nil {
+		return nil
+	}
+	durations := []duration{
+		{"loadBalancing.tryDuration", lb.TryDuration},
+		{"loadBalancing.tryInterval", lb.TryInterval},
+	}
+	if c := lb.Cookie; c != nil {
+		durations = append(durations, duration{"loadBalancing.cookie.maxAge", c.MaxAge})
+	}
+	return validateDurations(durations)
+}
+
+// validateTransport returns an error if a duration in the supplied
+// parameters' transport tuning is invalid.
+func validateTransport(p *v1alpha1.ProxyRouteParameters) error {
+	t := p.Transport
+	if t == nil {
+		return nil
+	}
+	durations := []duration{
+		{"transport.dialTimeout", t.DialTimeout},
+		{"transport.responseHeaderTimeout", t.ResponseHeaderTimeout},
+		{"transport.readTimeout", t.ReadTimeout},
+		{"transport.writeTimeout", t.WriteTimeout},
+	}
+	if k := t.KeepAlive; k != nil {
+		durations = append(durations,
+			duration{"transport.keepAlive.probeInterval", k.ProbeInterval},
+			duration{"transport.keepAlive.idleTimeout", k.IdleTimeout})
+	}
+	return validateDurations(durations)
+}
+
+// A duration is the value of a field that holds a Caddy duration string.
+type duration struct {
+	field string
+	value *string
 }
 
-// convertUpstreamStatuses converts Caddy client upstream statuses to CRD format.
-func convertUpstreamStatuses(upstreams []caddyclient.UpstreamStatus) []v1alpha1.UpstreamStatus {
-	statuses := make([]v1alpha1.UpstreamStatus, len(upstreams))
-	for i, u := range upstreams {
-		statuses[i] = v1alpha1.UpstreamStatus{
-			Address:     u.Address,
-			Healthy:     u.Healthy,
-			NumRequests: u.NumRequests,
+// validateDurations returns an error if any of the supplied durations that
+// are set don't parse. Caddy's duration syntax is Go's plus a day unit, so
+// e.g. "1d" is valid.
+func validateDurations(durations []duration) error {
+	for _, d := range durations {
+		if d.value == nil {
+			continue
+		}
+		if _, err := caddyclient.ParseDuration(*d.value); err != nil {
+			return errors.Wrapf(err, errFmtInvalidDuration, d.field)
 		}
 	}
-	return statuses
+	return nil
+}
+
+// validateHealthChecks returns an error if a duration, regular expression, or
+// circuit breaker config in the supplied parameters' health checks is
+// invalid.
+func validateHealthChecks(p *v1alpha1.ProxyRouteParameters) error {
+	hc := p.HealthChecks
+	if hc == nil {
+		return nil
+	}
+	var durations []duration
+	if a := hc.Active; a != nil {
+		durations = append(durations,
+			duration{"healthChecks.active.interval", a.Interval},
+			duration{"healthChecks.active.timeout", a.Timeout})
+		if a.ExpectBody != nil {
+			if _, err := regexp.Compile(*a.ExpectBody); err != nil {
+				return errors.Wrapf(err, errFmtInvalidRegexp, "healthChecks.active.expectBody")
+			}
+		}
+	}
+	if ps := hc.Passive; ps != nil {
+		durations = append(durations,
+			duration{"healthChecks.passive.failDuration", ps.FailDuration},
+			duration{"healthChecks.passive.unhealthyLatency", ps.UnhealthyLatency})
+	}
+	if cb := hc.CircuitBreaker; cb != nil && cb.Config != nil && len(cb.Config.Raw) > 0 {
+		var cfg map[string]any
+		if err := json.Unmarshal(cb.Config.Raw, &cfg); err != nil {
+			return errors.Wrap(err, errInvalidCircuitBreaker)
+		}
+		if cfg == nil {
+			return errors.New(errInvalidCircuitBreaker)
+		}
+	}
+	return validateDurations(durations)
+}
+
+// validateMatchSets returns an error if a regular expression in the supplied
+// parameters' match or retry match conditions is invalid. Caddy uses Go's
+// regular expression syntax, which the CRD schema can't validate.
+func validateMatchSets(p *v1alpha1.ProxyRouteParameters) error {
+	sets := matchSetsOf(p)
+	if lb := p.LoadBalancing; lb != nil {
+		sets = append(append([]v1alpha1.RouteMatch{}, sets...), lb.RetryMatch...)
+	}
+	for _, m := range sets {
+		conditions := append([]v1alpha1.MatchConditions{m.MatchConditions}, m.Not...)
+		for _, c := range conditions {
+			if c.PathRegexp != nil {
+				if _, err := regexp.Compile(c.PathRegexp.Pattern); err != nil {
+					return errors.Wrapf(err, errFmtInvalidRegexp, "pathRegexp")
+				}
+			}
+			for k, r := range c.HeaderRegexp {
+				if _, err := regexp.Compile(r.Pattern); err != nil {
+					return errors.Wrapf(err, errFmtInvalidRegexp, "headerRegexp "+k)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// convertMatchSet converts a set of CRD match conditions to the Caddy client
+// format.
+func convertMatchSet(m *v1alpha1.RouteMatch) caddyclient.MatchSet {
+	ms := convertMatchConditions(&m.MatchConditions)
+	for i := range m.Not {
+		ms.Not = append(ms.Not, convertMatchConditions(&m.Not[i]))
+	}
+	return ms
+}
+
+func convertMatchConditions(m *v1alpha1.MatchConditions) caddyclient.MatchSet {
+	ms := caddyclient.MatchSet{
+		Host:       m.Host,
+		Path:       m.Path,
+		Method:     m.Method,
+		Header:     m.Headers,
+		Query:      m.Query,
+		Protocol:   ptr.Deref(m.Protocol, ""),
+		Expression: ptr.Deref(m.Expression, ""),
+	}
+	if len(m.RemoteIP) > 0 {
+		ms.RemoteIP = &caddyclient.IPRanges{Ranges: ipRanges(m.RemoteIP)}
+	}
+	if len(m.ClientIP) > 0 {
+		ms.ClientIP = &caddyclient.IPRanges{Ranges: ipRanges(m.ClientIP)}
+	}
+	if m.PathRegexp != nil {
+		ms.PathRegexp = &caddyclient.Regexp{Name: ptr.Deref(m.PathRegexp.Name, ""), Pattern: m.PathRegexp.Pattern}
+	}
+	for k, v := range m.HeaderRegexp {
+		if ms.HeaderRegexp == nil {
+			ms.HeaderRegexp = map[string]caddyclient.Regexp{}
+		}
+		ms.HeaderRegexp[k] = caddyclient.Regexp{Name: ptr.Deref(v.Name, ""), Pattern: v.Pattern}
+	}
+	return ms
+}
+
+func ipRanges(in []v1alpha1.IPRange) []string {
+	out := make([]string, len(in))
+	for i, r := range in {
+		out[i] = string(r)
+	}
+	return out
 }
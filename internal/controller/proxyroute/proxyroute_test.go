@@ -0,0 +1,1113 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/v2/apis/common/v1"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/event"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/test"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-caddy/apis/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+	"github.com/crossplane/provider-caddy/internal/clients/caddy/fake"
+)
+
+// Unlike many Kubernetes projects Crossplane does not use third party testing
+// libraries, per the common Go test review comments. Crossplane encourages the
+// use of table driven unit tests. The tests of the crossplane-runtime project
+// are representative of the testing style Crossplane encourages.
+//
+// https://github.com/golang/go/wiki/TestComments
+// https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
+
+// Caddy routes as the ProxyRoute built by proxyRoute configures them.
+const (
+	routeA        = `{"@id":"proxyroute-a","match":[{"host":["example.com"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"a:80"}]}],"terminal":true}`
+	routeADrifted = `{"@id":"proxyroute-a","match":[{"host":["example.com"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"b:80"}]}],"terminal":true}`
+
+	// routeB is another route that ranks the same as routeA.
+	routeB = `{"@id":"proxyroute-b","match":[{"host":["example.org"]}],"handle":[{"handler":"reverse_proxy","upstreams":[{"dial":"b:80"}]}],"terminal":true}`
+)
+
+type proxyRouteModifier func(cr *v1alpha1.ProxyRoute)
+
+func withExternalName(n string) proxyRouteModifier {
+	return func(cr *v1alpha1.ProxyRoute) { meta.SetExternalName(cr, n) }
+}
+
+func withDial(d string) proxyRouteModifier {
+	return func(cr *v1alpha1.ProxyRoute) { cr.Spec.ForProvider.Upstreams = []v1alpha1.Upstream{{Dial: d}} }
+}
+
+func withPriority(p int) proxyRouteModifier {
+	return func(cr *v1alpha1.ProxyRoute) { cr.Spec.ForProvider.Priority = &p }
+}
+
+// proxyRoute returns a ProxyRoute with UID "a" that proxies requests for
+// example.com to a:80.
+func proxyRoute(m ...proxyRouteModifier) *v1alpha1.ProxyRoute {
+	cr := &v1alpha1.ProxyRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", UID: types.UID("a")},
+		Spec: v1alpha1.ProxyRouteSpec{ForProvider: v1alpha1.ProxyRouteParameters{
+			Match:     &v1alpha1.RouteMatch{MatchConditions: v1alpha1.MatchConditions{Host: []string{"example.com"}}},
+			Upstreams: []v1alpha1.Upstream{{Dial: "a:80"}},
+		}},
+	}
+	for _, fn := range m {
+		fn(cr)
+	}
+	return cr
+}
+
+// namespacedProxyRoute returns a namespaced ProxyRoute with UID "a" and the
+// supplied external name, that proxies requests for example.com to a:80.
+func namespacedProxyRoute(externalName string) *namespacedv1alpha1.ProxyRoute {
+	cr := &namespacedv1alpha1.ProxyRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "a", UID: types.UID("a")},
+		Spec: namespacedv1alpha1.ProxyRouteSpec{ForProvider: v1alpha1.ProxyRouteParameters{
+			Match:     &v1alpha1.RouteMatch{MatchConditions: v1alpha1.MatchConditions{Host: []string{"example.com"}}},
+			Upstreams: []v1alpha1.Upstream{{Dial: "a:80"}},
+		}},
+	}
+	meta.SetExternalName(cr, externalName)
+	return cr
+}
+
+// newExternal returns an external that connects to a fake Caddy admin API
+// configured with the supplied routes of server srv0.
+func newExternal(t *testing.T, routes ...string) (*external, *fake.Server) {
+	t.Helper()
+	srv := fake.NewServer(fake.WithConfig(`{"apps":{"http":{"servers":{"srv0":{"routes":[` + strings.Join(routes, ",") + `]}}}}}`))
+	t.Cleanup(srv.Close)
+
+	cl, err := caddyclient.NewClient(srv.URL, caddyclient.WithRequestOptions(caddyclient.RequestOptions{}))
+	if err != nil {
+		t.Fatalf("caddyclient.NewClient(...): %v", err)
+	}
+	return &external{
+		kube:     &test.MockClient{MockList: test.NewMockListFn(nil)},
+		client:   cl,
+		ordering: apisv1alpha1.RouteOrderingPriority,
+		logger:   logging.NewNopLogger(),
+		recorder: event.NewNopRecorder(),
+	}, srv
+}
+
+func TestObserve(t *testing.T) {
+	type fields struct {
+		routes []string
+	}
+
+	type args struct {
+		ctx context.Context
+		mg  resource.Managed
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields fields
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "A route that doesn't exist should be reported as such.",
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"LateInitialized": {
+			reason: "A route created without recording its external name should be found by its @id.",
+			fields: fields{routes: []string{routeA}},
+			args:   args{ctx: context.Background(), mg: proxyRoute()},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: true}},
+		},
+		"UpToDate": {
+			reason: "A route that matches the desired state should be up to date.",
+			fields: fields{routes: []string{routeA}},
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"EqualRankBefore": {
+			reason: "A route before a route of equal rank should be in order.",
+			fields: fields{routes: []string{routeA, routeB}},
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"EqualRankAfter": {
+			reason: "A route after a route of equal rank should be in order.",
+			fields: fields{routes: []string{routeB, routeA}},
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"OutOfOrder": {
+			reason: "A route after a route it outranks should not be up to date.",
+			fields: fields{routes: []string{routeB, routeA}},
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"), withPriority(1))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"NamespacedOwnRoute": {
+			reason: "A namespaced ProxyRoute should manage the route it created.",
+			fields: fields{routes: []string{routeA, routeB}},
+			args:   args{ctx: context.Background(), mg: namespacedProxyRoute("proxyroute-a")},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"NamespacedForeignRoute": {
+			reason: "A namespaced ProxyRoute should not manage a route it didn't create, whatever its external name.",
+			fields: fields{routes: []string{routeA, routeB}},
+			args:   args{ctx: context.Background(), mg: namespacedProxyRoute("proxyroute-b")},
+			want:   want{err: errors.Errorf(errFmtForeignRoute, "proxyroute-b", "proxyroute-a")},
+		},
+		"Drifted": {
+			reason: "A route that differs from the desired state should not be up to date.",
+			fields: fields{routes: []string{routeADrifted}},
+			args:   args{ctx: context.Background(), mg: proxyRoute(withExternalName("proxyroute-a"))},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, _ := newExternal(t, tc.fields.routes...)
+			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got, cmpopts.IgnoreFields(managed.ExternalObservation{}, "Diff")); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveUpstreamHealth(t *testing.T) {
+	withPassive := func(maxFails *int) proxyRouteModifier {
+		return func(cr *v1alpha1.ProxyRoute) {
+			cr.Spec.ForProvider.HealthChecks = &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{FailDuration: ptr.To("30s"), MaxFails: maxFails}}
+		}
+	}
+	withMinHealthy := func(n int) proxyRouteModifier {
+		return func(cr *v1alpha1.ProxyRoute) { cr.Spec.ForProvider.MinHealthyUpstreams = &n }
+	}
+
+	type args struct {
+		upstreams []fake.Upstream
+		mg        *v1alpha1.ProxyRoute
+	}
+	type want struct {
+		health   xpv1.ConditionReason
+		ready    xpv1.ConditionReason
+		statuses []v1alpha1.UpstreamStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoHealthChecks": {
+			reason: "Without passive health checks Caddy reports no health, so health should be unknown but not keep the route from becoming Ready.",
+			args: args{
+				upstreams: []fake.Upstream{{Address: "a:80", NumRequests: 1}},
+				mg:        proxyRoute(withExternalName("proxyroute-a"), withMinHealthy(1)),
+			},
+			want: want{
+				health:   v1alpha1.ReasonNoHealthData,
+				ready:    xpv1.ReasonAvailable,
+				statuses: []v1alpha1.UpstreamStatus{{Address: "a:80", NumRequests: 1}},
+			},
+		},
+		"NoFailDuration": {
+			reason: "Passive health checks without a fail duration don't count failures, so health should be unknown.",
+			args: args{
+				upstreams: []fake.Upstream{{Address: "a:80"}},
+				mg: proxyRoute(withExternalName("proxyroute-a"), func(cr *v1alpha1.ProxyRoute) {
+					cr.Spec.ForProvider.HealthChecks = &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{MaxFails: ptr.To(3)}}
+				}),
+			},
+			want: want{
+				health:   v1alpha1.ReasonNoHealthData,
+				ready:    xpv1.ReasonAvailable,
+				statuses: []v1alpha1.UpstreamStatus{{Address: "a:80"}},
+			},
+		},
+		"Healthy": {
+			reason: "An upstream without fails should be healthy, and make the route Ready.",
+			args: args{
+				upstreams: []fake.Upstream{{Address: "a:80"}},
+				mg:        proxyRoute(withExternalName("proxyroute-a"), withPassive(nil), withMinHealthy(1)),
+			},
+			want: want{
+				health:   v1alpha1.ReasonAllHealthy,
+				ready:    xpv1.ReasonAvailable,
+				statuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(true)}},
+			},
+		},
+		"FailsBelowMaxFails": {
+			reason: "An upstream whose fails haven't reached maxFails should be healthy.",
+			args: args{
+				upstreams: []fake.Upstream{{Address: "a:80", Fails: 2}},
+				mg:        proxyRoute(withExternalName("proxyroute-a"), withPassive(ptr.To(3)), withMinHealthy(1)),
+			},
+			want: want{
+				health:   v1alpha1.ReasonAllHealthy,
+				ready:    xpv1.ReasonAvailable,
+				statuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(true), Fails: 2}},
+			},
+		},
+		"FailsReachedMaxFails": {
+			reason: "An upstream whose fails reached Caddy's default maxFails of 1 should be unhealthy, and keep the route from becoming Ready.",
+			args: args{
+				upstreams: []fake.Upstream{{Address: "a:80", Fails: 1}},
+				mg:        proxyRoute(withExternalName("proxyroute-a"), withPassive(nil), withMinHealthy(1)),
+			},
+			want: want{
+				health:   v1alpha1.ReasonAllUnhealthy,
+				ready:    reasonInsufficientHealthyUpstreams,
+				statuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(false), Fails: 1}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, srv := newExternal(t, routeA)
+			srv.SetUpstreams(tc.args.upstreams...)
+
+			if _, err := e.Observe(context.Background(), tc.args.mg); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.health, tc.args.mg.GetCondition(v1alpha1.TypeUpstreamsHealthy).Reason); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want UpstreamsHealthy reason, +got UpstreamsHealthy reason:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ready, tc.args.mg.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want Ready reason, +got Ready reason:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.statuses, tc.args.mg.Status.AtProvider.UpstreamStatuses); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want upstream statuses, +got upstream statuses:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		routes       []caddyclient.ProxyRoute
+		externalName string
+		err          error
+	}
+
+	cases := map[string]struct {
+		reason string
+		routes []string
+		mg     resource.Managed
+		want   want
+	}{
+		"Create": {
+			reason: "A route tagged with an @id derived from the UID should be created.",
+			mg:     proxyRoute(),
+			want: want{
+				routes:       []caddyclient.ProxyRoute{*mustRoute(routeA)},
+				externalName: "proxyroute-a",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, srv := newExternal(t, tc.routes...)
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.externalName, meta.GetExternalName(tc.mg)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.routes, srv.Routes("srv0")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type want struct {
+		routes []caddyclient.ProxyRoute
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		routes []string
+		mg     resource.Managed
+		want   want
+	}{
+		"Update": {
+			reason: "A drifted route should be updated to the desired state.",
+			routes: []string{routeADrifted},
+			mg:     proxyRoute(withExternalName("proxyroute-a")),
+			want:   want{routes: []caddyclient.ProxyRoute{*mustRoute(routeA)}},
+		},
+		"EqualRankNotMoved": {
+			reason: "Updating a route should not move it relative to a route of equal rank.",
+			routes: []string{routeADrifted, routeB},
+			mg:     proxyRoute(withExternalName("proxyroute-a")),
+			want:   want{routes: []caddyclient.ProxyRoute{*mustRoute(routeA), *mustRoute(routeB)}},
+		},
+		"Reordered": {
+			reason: "Updating a route should move it before a route it outranks.",
+			routes: []string{routeB, routeADrifted},
+			mg:     proxyRoute(withExternalName("proxyroute-a"), withPriority(1)),
+			want:   want{routes: []caddyclient.ProxyRoute{*mustRoute(routeA), *mustRoute(routeB)}},
+		},
+		"UpdateUpstreams": {
+			reason: "A route should be updated when its upstreams change.",
+			routes: []string{routeA},
+			mg:     proxyRoute(withExternalName("proxyroute-a"), withDial("b:80")),
+			want:   want{routes: []caddyclient.ProxyRoute{*mustRoute(routeADrifted)}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, srv := newExternal(t, tc.routes...)
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.routes, srv.Routes("srv0")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want routes, +got routes:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func mustRoute(s string) *caddyclient.ProxyRoute {
+	r := &caddyclient.ProxyRoute{}
+	if err := json.Unmarshal([]byte(s), r); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestProviderConfig(t *testing.T) {
+	type want struct {
+		endpoint string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		source xpv1.CredentialsSource
+		want   want
+	}{
+		"SecretSource": {
+			reason: "A namespaced ProviderConfig may load credentials from a Secret.",
+			source: xpv1.CredentialsSourceSecret,
+			want:   want{endpoint: "http://caddy:2019"},
+		},
+		"NoneSource": {
+			reason: "A namespaced ProviderConfig may use no credentials.",
+			source: xpv1.CredentialsSourceNone,
+			want:   want{endpoint: "http://caddy:2019"},
+		},
+		"EnvironmentSource": {
+			reason: "A namespaced ProviderConfig must not load credentials from the provider's environment.",
+			source: xpv1.CredentialsSourceEnvironment,
+			want:   want{err: errors.Errorf(errFmtNamespacedPCSource, xpv1.CredentialsSourceEnvironment)},
+		},
+		"FilesystemSource": {
+			reason: "A namespaced ProviderConfig must not load credentials from the provider's filesystem.",
+			source: xpv1.CredentialsSourceFilesystem,
+			want:   want{err: errors.Errorf(errFmtNamespacedPCSource, xpv1.CredentialsSourceFilesystem)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &connector{kube: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				pc, ok := obj.(*apisv1alpha1.ProviderConfig)
+				if !ok {
+					return errors.New("unexpected object")
+				}
+				pc.SetNamespace("team-a")
+				pc.Spec.Endpoint = "http://caddy:2019"
+				pc.Spec.Credentials.Source = tc.source
+				return nil
+			}}}
+			mg := &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "a"}}
+			mg.SetProviderConfigReference(&xpv1.ProviderConfigReference{Kind: apisv1alpha1.ProviderConfigKind, Name: "caddy"})
+
+			got, err := c.providerConfig(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.providerConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			endpoint := ""
+			if got != nil {
+				endpoint = got.Endpoint
+			}
+			if diff := cmp.Diff(tc.want.endpoint, endpoint); diff != "" {
+				t.Errorf("\n%s\nc.providerConfig(...): -want endpoint, +got endpoint:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	withEndpoint := func(e string) proxyRouteModifier {
+		return func(cr *v1alpha1.ProxyRoute) { cr.Spec.ForProvider.CaddyEndpoint = &e }
+	}
+	withPCRef := func(cr *v1alpha1.ProxyRoute) {
+		cr.SetProviderConfigReference(&xpv1.Reference{Name: "caddy"})
+	}
+
+	type args struct {
+		endpoint string
+		caBundle []byte
+		mg       resource.Managed
+	}
+	type want struct {
+		err    error
+		reason xpv1.ConditionReason
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ProviderConfigEndpoint": {
+			reason: "A ProxyRoute should connect to its ProviderConfig's endpoint.",
+			args:   args{endpoint: "http://caddy:2019", mg: proxyRoute(withPCRef)},
+		},
+		"RouteEndpoint": {
+			reason: "A cluster scoped ProxyRoute should connect to its own endpoint if its ProviderConfig has none.",
+			args:   args{mg: proxyRoute(withPCRef, withEndpoint("http://caddy:2019"))},
+		},
+		"NoProviderConfigRef": {
+			reason: "A ProxyRoute should not connect if it doesn't reference a ProviderConfig.",
+			args:   args{endpoint: "http://caddy:2019", mg: proxyRoute()},
+			want:   want{err: errors.Wrap(errors.New(errNoPCRef), errGetPC)},
+		},
+		"NoEndpoint": {
+			reason: "A ProxyRoute should not connect if neither it nor its ProviderConfig specifies an endpoint.",
+			args:   args{mg: proxyRoute(withPCRef)},
+			want:   want{err: errors.New(errNoEndpoint), reason: reasonInvalidConfig},
+		},
+		"NamespacedNoEndpoint": {
+			reason: "A namespaced ProxyRoute should not connect if its ProviderConfig specifies no endpoint.",
+			args: args{mg: func() resource.Managed {
+				cr := &namespacedv1alpha1.ProxyRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "a"}}
+				cr.SetProviderConfigReference(&xpv1.ProviderConfigReference{Kind: apisv1alpha1.ClusterProviderConfigKind, Name: "caddy"})
+				return cr
+			}()},
+			want: want{err: errors.New(errNoEndpoint), reason: reasonInvalidConfig},
+		},
+		"InvalidCABundle": {
+			reason: "A CA bundle that can't be parsed should be reported like credentials that can't be loaded.",
+			args:   args{endpoint: "https://caddy:2019", caBundle: []byte("not a certificate"), mg: proxyRoute(withPCRef)},
+			want: want{
+				err:    errors.Wrap(fmt.Errorf("%w: failed to parse CA bundle: no PEM encoded certificates found", caddyclient.ErrInvalidTLSConfig), errNewClient),
+				reason: reasonCredentialsUnavailable,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &connector{
+				kube: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *apisv1alpha1.ClusterProviderConfig:
+						o.Spec.Endpoint = tc.args.endpoint
+						o.Spec.Credentials.Source = xpv1.CredentialsSourceNone
+						if tc.args.caBundle != nil {
+							o.Spec.TLS = &apisv1alpha1.AdminTLS{SecretRef: &xpv1.SecretReference{Namespace: "crossplane-system", Name: "caddy-tls"}}
+						}
+					case *corev1.Secret:
+						o.Data = map[string][]byte{keyCACert: tc.args.caBundle}
+					default:
+						return errors.New("unexpected object")
+					}
+					return nil
+				}},
+				usage:  resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+				logger: logging.NewNopLogger(),
+			}
+			_, err := c.Connect(context.Background(), tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, tc.args.mg.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want Ready reason, +got Ready reason:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConvertHealthChecks(t *testing.T) {
+	type want struct {
+		healthChecks   *caddyclient.HealthChecks
+		circuitBreaker map[string]any
+	}
+
+	cases := map[string]struct {
+		reason string
+		hc     *v1alpha1.HealthChecks
+		want   want
+	}{
+		"NoHealthChecks": {
+			reason: "A route without health checks should not configure them.",
+		},
+		"Active": {
+			reason: "Each active health check option should be converted to Caddy's.",
+			hc: &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{
+				URI:             ptr.To("/healthz?full=1"),
+				Port:            ptr.To(8081),
+				Headers:         map[string][]string{"Host": {"example.com"}},
+				Interval:        ptr.To("1d"),
+				Timeout:         ptr.To("5s"),
+				Passes:          ptr.To(2),
+				Fails:           ptr.To(3),
+				FollowRedirects: ptr.To(true),
+				MaxSize:         ptr.To(int64(1024)),
+				ExpectStatus:    ptr.To(200),
+				ExpectBody:      ptr.To("^ok$"),
+			}},
+			want: want{healthChecks: &caddyclient.HealthChecks{Active: &caddyclient.ActiveHealthCheck{
+				URI:             "/healthz?full=1",
+				Port:            8081,
+				Headers:         map[string][]string{"Host": {"example.com"}},
+				FollowRedirects: true,
+				Interval:        "1d",
+				Timeout:         "5s",
+				Passes:          2,
+				Fails:           3,
+				MaxSize:         1024,
+				ExpectStatus:    200,
+				ExpectBody:      "^ok$",
+			}}},
+		},
+		"ActivePath": {
+			reason: "An active health check's path should be converted to Caddy's.",
+			hc:     &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{Path: ptr.To("/healthz")}},
+			want:   want{healthChecks: &caddyclient.HealthChecks{Active: &caddyclient.ActiveHealthCheck{Path: "/healthz"}}},
+		},
+		"Passive": {
+			reason: "Each passive health check option should be converted to Caddy's.",
+			hc: &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{
+				FailDuration:          ptr.To("1d"),
+				MaxFails:              ptr.To(3),
+				UnhealthyStatus:       []int{502, 503},
+				UnhealthyLatency:      ptr.To("500ms"),
+				UnhealthyRequestCount: ptr.To(100),
+			}},
+			want: want{healthChecks: &caddyclient.HealthChecks{Passive: &caddyclient.PassiveHealthCheck{
+				FailDuration:          "1d",
+				MaxFails:              3,
+				UnhealthyStatus:       []int{502, 503},
+				UnhealthyLatency:      "500ms",
+				UnhealthyRequestCount: 100,
+			}}},
+		},
+		"CircuitBreaker": {
+			reason: "A circuit breaker's config should be inlined with its module's type.",
+			hc: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`{"factor":"latency","threshold":500,"trip_time":"10s"}`)},
+			}},
+			want: want{
+				healthChecks:   &caddyclient.HealthChecks{},
+				circuitBreaker: map[string]any{"type": "simple", "factor": "latency", "threshold": float64(500), "trip_time": "10s"},
+			},
+		},
+		"CircuitBreakerTypeWins": {
+			reason: "A circuit breaker's type should not be overridden by its config.",
+			hc: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`{"type":"other"}`)},
+			}},
+			want: want{
+				healthChecks:   &caddyclient.HealthChecks{},
+				circuitBreaker: map[string]any{"type": "simple"},
+			},
+		},
+		"CircuitBreakerNoConfig": {
+			reason: "A circuit breaker without config should only specify its module's type.",
+			hc:     &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{Type: "simple"}},
+			want: want{
+				healthChecks:   &caddyclient.HealthChecks{},
+				circuitBreaker: map[string]any{"type": "simple"},
+			},
+		},
+		"CircuitBreakerNullConfig": {
+			reason: "A circuit breaker whose config isn't an object, which validate rejects, should only specify its module's type when observed.",
+			hc: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`null`)},
+			}},
+			want: want{
+				healthChecks:   &caddyclient.HealthChecks{},
+				circuitBreaker: map[string]any{"type": "simple"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := convertToProxyRoute(&v1alpha1.ProxyRouteParameters{HealthChecks: tc.hc}).Handle[0]
+			got := want{healthChecks: h.HealthChecks, circuitBreaker: h.CircuitBreaker}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nconvertToProxyRoute(...): -want health checks, +got health checks:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConvertDynamicUpstreams(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		d      *v1alpha1.DynamicUpstreams
+		want   *caddyclient.DynamicUpstreams
+	}{
+		"NoDynamicUpstreams": {
+			reason: "A route with static upstreams should not configure dynamic ones.",
+		},
+		"SRV": {
+			reason: "Each option of an SRV source should be converted to Caddy's, with the source in the case of its module's name.",
+			d: &v1alpha1.DynamicUpstreams{
+				Source:            v1alpha1.DynamicUpstreamsSourceSRV,
+				Name:              "_http._tcp.backend.example.com",
+				Refresh:           ptr.To("1d"),
+				Resolvers:         []string{"10.0.0.10:53"},
+				DialTimeout:       ptr.To("5s"),
+				DialFallbackDelay: ptr.To("-1s"),
+			},
+			want: &caddyclient.DynamicUpstreams{
+				Source:            "srv",
+				Name:              "_http._tcp.backend.example.com",
+				Refresh:           "1d",
+				Resolver:          &caddyclient.Resolver{Addresses: []string{"10.0.0.10:53"}},
+				DialTimeout:       "5s",
+				DialFallbackDelay: "-1s",
+			},
+		},
+		"A": {
+			reason: "The port of an A source should be converted to Caddy's string.",
+			d: &v1alpha1.DynamicUpstreams{
+				Source: v1alpha1.DynamicUpstreamsSourceA,
+				Name:   "backend.example.com",
+				Port:   ptr.To(8080),
+			},
+			want: &caddyclient.DynamicUpstreams{Source: "a", Name: "backend.example.com", Port: "8080"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := convertToProxyRoute(&v1alpha1.ProxyRouteParameters{DynamicUpstreams: tc.d}).Handle[0].DynamicUpstreams
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nconvertToProxyRoute(...): -want dynamic upstreams, +got dynamic upstreams:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConvertLoadBalancing(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		lb     v1alpha1.LoadBalancing
+		want   *caddyclient.LoadBalancing
+	}{
+		"NoPolicy": {
+			reason: "Retry options should be converted without a selection policy if none is specified.",
+			lb: v1alpha1.LoadBalancing{
+				TryDuration: ptr.To("1d"),
+				TryInterval: ptr.To("250ms"),
+				Retries:     ptr.To(3),
+				RetryMatch:  []v1alpha1.RouteMatch{{MatchConditions: v1alpha1.MatchConditions{Method: []string{"GET", "PUT"}}}},
+			},
+			want: &caddyclient.LoadBalancing{
+				TryDuration: "1d",
+				TryInterval: "250ms",
+				Retries:     3,
+				RetryMatch:  []caddyclient.MatchSet{{Method: []string{"GET", "PUT"}}},
+			},
+		},
+		"Policy": {
+			reason: "A policy without options should be converted to a selection policy.",
+			lb:     v1alpha1.LoadBalancing{Policy: ptr.To("least_conn")},
+			want:   &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "least_conn"}},
+		},
+		"WeightedRoundRobin": {
+			reason: "The weighted_round_robin policy's weights should be left for desiredRoute to set from the upstreams.",
+			lb:     v1alpha1.LoadBalancing{Policy: ptr.To("weighted_round_robin")},
+			want:   &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "weighted_round_robin"}},
+		},
+		"Header": {
+			reason: "The header policy's field and fallback should be converted to Caddy's.",
+			lb: v1alpha1.LoadBalancing{
+				Policy:   ptr.To("header"),
+				Header:   &v1alpha1.HeaderPolicy{Field: "X-Tenant"},
+				Fallback: ptr.To("round_robin"),
+			},
+			want: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{
+				Policy:   "header",
+				Field:    "X-Tenant",
+				Fallback: &caddyclient.SelectionPolicy{Policy: "round_robin"},
+			}},
+		},
+		"Query": {
+			reason: "The query policy's key should be converted to Caddy's.",
+			lb:     v1alpha1.LoadBalancing{Policy: ptr.To("query"), Query: &v1alpha1.QueryPolicy{Key: "tenant"}},
+			want:   &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{Policy: "query", Key: "tenant"}},
+		},
+		"Cookie": {
+			reason: "The cookie policy's name and max age should be converted to Caddy's, leaving the secret for desiredRoute to set.",
+			lb: v1alpha1.LoadBalancing{
+				Policy: ptr.To("cookie"),
+				Cookie: &v1alpha1.CookiePolicy{
+					Name:      ptr.To("sticky"),
+					MaxAge:    ptr.To("1h"),
+					SecretRef: &v1alpha1.SecretKeyReference{Name: "lb-cookie", Key: "secret"},
+				},
+				Fallback: ptr.To("least_conn"),
+			},
+			want: &caddyclient.LoadBalancing{SelectionPolicy: &caddyclient.SelectionPolicy{
+				Policy:   "cookie",
+				Name:     "sticky",
+				MaxAge:   "1h",
+				Fallback: &caddyclient.SelectionPolicy{Policy: "least_conn"},
+			}},
+		},
+		"OptionsWithoutPolicy": {
+			reason: "Policy options should be ignored if no policy is specified.",
+			lb: v1alpha1.LoadBalancing{
+				Header:   &v1alpha1.HeaderPolicy{Field: "X-Tenant"},
+				Fallback: ptr.To("round_robin"),
+			},
+			want: &caddyclient.LoadBalancing{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := convertLoadBalancing(&tc.lb)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nconvertLoadBalancing(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConvertTransport(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.ProxyRouteParameters
+		want   *caddyclient.Transport
+	}{
+		"NoTransport": {
+			reason: "A route without transport tuning or TLS should use Caddy's default transport.",
+		},
+		"Empty": {
+			reason: "Empty transport tuning should configure Caddy's HTTP transport.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{}},
+			want:   &caddyclient.Transport{Protocol: "http"},
+		},
+		"Full": {
+			reason: "Each transport tuning option should be converted to Caddy's.",
+			p: v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{
+				DialTimeout:           ptr.To("3s"),
+				ResponseHeaderTimeout: ptr.To("1m"),
+				ReadTimeout:           ptr.To("1d"),
+				WriteTimeout:          ptr.To("30s"),
+				KeepAlive: &v1alpha1.KeepAlive{
+					Enabled:             ptr.To(true),
+					ProbeInterval:       ptr.To("30s"),
+					MaxIdleConns:        ptr.To(100),
+					MaxIdleConnsPerHost: ptr.To(10),
+					IdleTimeout:         ptr.To("2m"),
+				},
+				Versions:        []string{"h2c"},
+				MaxConnsPerHost: ptr.To(50),
+				ReadBufferSize:  ptr.To(4096),
+				WriteBufferSize: ptr.To(8192),
+				ProxyProtocol:   ptr.To("v2"),
+				Resolvers:       []string{"10.0.0.10:53"},
+			}},
+			want: &caddyclient.Transport{
+				Protocol:              "http",
+				Resolver:              &caddyclient.Resolver{Addresses: []string{"10.0.0.10:53"}},
+				DialTimeout:           "3s",
+				ResponseHeaderTimeout: "1m",
+				ReadTimeout:           "1d",
+				WriteTimeout:          "30s",
+				KeepAlive: &caddyclient.KeepAlive{
+					Enabled:             ptr.To(true),
+					ProbeInterval:       "30s",
+					MaxIdleConns:        100,
+					MaxIdleConnsPerHost: 10,
+					IdleTimeout:         "2m",
+				},
+				Versions:        []string{"h2c"},
+				MaxConnsPerHost: 50,
+				ReadBufferSize:  4096,
+				WriteBufferSize: 8192,
+				ProxyProtocol:   "v2",
+			},
+		},
+		"KeepAliveDisabled": {
+			reason: "Disabling keep-alive should be sent to Caddy, rather than omitted as though it were unset.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{KeepAlive: &v1alpha1.KeepAlive{Enabled: ptr.To(false)}}},
+			want:   &caddyclient.Transport{Protocol: "http", KeepAlive: &caddyclient.KeepAlive{Enabled: ptr.To(false)}},
+		},
+		"TLS": {
+			reason: "Upstream TLS should be added to the tuned transport.",
+			p: v1alpha1.ProxyRouteParameters{
+				Transport: &v1alpha1.HTTPTransport{DialTimeout: ptr.To("3s")},
+				TLS:       &v1alpha1.UpstreamTLS{Enabled: ptr.To(true), ServerName: ptr.To("backend.internal")},
+			},
+			want: &caddyclient.Transport{Protocol: "http", DialTimeout: "3s", TLS: &caddyclient.TLSConfig{ServerName: "backend.internal"}},
+		},
+		"TLSOnly": {
+			reason: "Upstream TLS without transport tuning should configure Caddy's HTTP transport.",
+			p:      v1alpha1.ProxyRouteParameters{TLS: &v1alpha1.UpstreamTLS{Enabled: ptr.To(true), InsecureSkipVerify: ptr.To(true)}},
+			want:   &caddyclient.Transport{Protocol: "http", TLS: &caddyclient.TLSConfig{InsecureSkipVerify: true}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := convertToProxyRoute(&tc.p).Handle[0].Transport
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nconvertToProxyRoute(...): -want transport, +got transport:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// invalidDuration returns the error validate returns for the supplied
+	// field's invalid duration.
+	invalidDuration := func(field, d string) error {
+		_, err := caddyclient.ParseDuration(d)
+		return errors.Wrapf(err, errFmtInvalidDuration, field)
+	}
+	// invalidRegexp returns the error validate returns for the supplied
+	// field's invalid regular expression.
+	invalidRegexp := func(field, re string) error {
+		_, err := regexp.Compile(re)
+		return errors.Wrapf(err, errFmtInvalidRegexp, field)
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.ProxyRouteParameters
+		want   error
+	}{
+		"NoOptions": {
+			reason: "A route without options that take durations or patterns should be valid.",
+		},
+		"ValidDynamicUpstreams": {
+			reason: "Dynamic upstreams with valid durations should be valid, including durations in Caddy's day unit and a negative fallback delay.",
+			p: v1alpha1.ProxyRouteParameters{DynamicUpstreams: &v1alpha1.DynamicUpstreams{
+				Source:            v1alpha1.DynamicUpstreamsSourceSRV,
+				Name:              "_http._tcp.backend.example.com",
+				Refresh:           ptr.To("1d"),
+				DialTimeout:       ptr.To("5s"),
+				DialFallbackDelay: ptr.To("-1s"),
+			}},
+		},
+		"InvalidDynamicUpstreamsRefresh": {
+			reason: "A dynamic upstreams refresh interval that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{DynamicUpstreams: &v1alpha1.DynamicUpstreams{Refresh: ptr.To("hourly")}},
+			want:   invalidDuration("dynamicUpstreams.refresh", "hourly"),
+		},
+		"InvalidDynamicUpstreamsDialTimeout": {
+			reason: "A dynamic upstreams dial timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{DynamicUpstreams: &v1alpha1.DynamicUpstreams{DialTimeout: ptr.To("5")}},
+			want:   invalidDuration("dynamicUpstreams.dialTimeout", "5"),
+		},
+		"InvalidDynamicUpstreamsDialFallbackDelay": {
+			reason: "A dynamic upstreams dial fallback delay that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{DynamicUpstreams: &v1alpha1.DynamicUpstreams{DialFallbackDelay: ptr.To("300 ms")}},
+			want:   invalidDuration("dynamicUpstreams.dialFallbackDelay", "300 ms"),
+		},
+		"ValidLoadBalancing": {
+			reason: "Load balancing with valid durations should be valid, including durations in Caddy's day unit.",
+			p: v1alpha1.ProxyRouteParameters{LoadBalancing: &v1alpha1.LoadBalancing{
+				TryDuration: ptr.To("30s"),
+				TryInterval: ptr.To("250ms"),
+				Cookie:      &v1alpha1.CookiePolicy{MaxAge: ptr.To("7d")},
+			}},
+		},
+		"InvalidTryDuration": {
+			reason: "A try duration that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{LoadBalancing: &v1alpha1.LoadBalancing{TryDuration: ptr.To("30")}},
+			want:   invalidDuration("loadBalancing.tryDuration", "30"),
+		},
+		"InvalidTryInterval": {
+			reason: "A try interval that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{LoadBalancing: &v1alpha1.LoadBalancing{TryInterval: ptr.To("quickly")}},
+			want:   invalidDuration("loadBalancing.tryInterval", "quickly"),
+		},
+		"InvalidCookieMaxAge": {
+			reason: "A cookie max age that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{LoadBalancing: &v1alpha1.LoadBalancing{Cookie: &v1alpha1.CookiePolicy{MaxAge: ptr.To("1y")}}},
+			want:   invalidDuration("loadBalancing.cookie.maxAge", "1y"),
+		},
+		"ValidActiveHealthChecks": {
+			reason: "Active health checks with valid durations and pattern should be valid, including durations in Caddy's day unit.",
+			p: v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{
+				Interval:   ptr.To("1d"),
+				Timeout:    ptr.To("1m30s"),
+				ExpectBody: ptr.To("^ok$"),
+			}}},
+		},
+		"InvalidActiveInterval": {
+			reason: "An active health check interval that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{Interval: ptr.To("1w")}}},
+			want:   invalidDuration("healthChecks.active.interval", "1w"),
+		},
+		"InvalidActiveTimeout": {
+			reason: "An active health check timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{Timeout: ptr.To("30")}}},
+			want:   invalidDuration("healthChecks.active.timeout", "30"),
+		},
+		"InvalidActiveExpectBody": {
+			reason: "An active health check body pattern that isn't a Go regular expression should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Active: &v1alpha1.ActiveHealthCheck{ExpectBody: ptr.To("(ok")}}},
+			want:   invalidRegexp("healthChecks.active.expectBody", "(ok"),
+		},
+		"ValidPassiveHealthChecks": {
+			reason: "Passive health checks with valid durations should be valid, including durations in Caddy's day unit.",
+			p: v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{
+				FailDuration:     ptr.To("1d"),
+				UnhealthyLatency: ptr.To("500ms"),
+			}}},
+		},
+		"InvalidPassiveFailDuration": {
+			reason: "A passive health check fail duration that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{FailDuration: ptr.To("forever")}}},
+			want:   invalidDuration("healthChecks.passive.failDuration", "forever"),
+		},
+		"InvalidPassiveUnhealthyLatency": {
+			reason: "A passive health check latency threshold that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{UnhealthyLatency: ptr.To("500")}}},
+			want:   invalidDuration("healthChecks.passive.unhealthyLatency", "500"),
+		},
+		"ValidCircuitBreaker": {
+			reason: "A circuit breaker whose config is a JSON object should be valid.",
+			p: v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`{"threshold":500}`)},
+			}}},
+		},
+		"CircuitBreakerConfigArray": {
+			reason: "A circuit breaker whose config is a JSON array should be invalid.",
+			p: v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`[500]`)},
+			}}},
+			want: errors.Wrap(json.Unmarshal([]byte(`[500]`), &map[string]any{}), errInvalidCircuitBreaker),
+		},
+		"CircuitBreakerConfigNull": {
+			reason: "A circuit breaker whose config is null should be invalid.",
+			p: v1alpha1.ProxyRouteParameters{HealthChecks: &v1alpha1.HealthChecks{CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Type:   "simple",
+				Config: &runtime.RawExtension{Raw: []byte(`null`)},
+			}}},
+			want: errors.New(errInvalidCircuitBreaker),
+		},
+		"ValidTransport": {
+			reason: "Transport tuning with valid durations should be valid, including durations in Caddy's day unit.",
+			p: v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{
+				DialTimeout:           ptr.To("3s"),
+				ResponseHeaderTimeout: ptr.To("1m"),
+				ReadTimeout:           ptr.To("1d"),
+				WriteTimeout:          ptr.To("30s"),
+				KeepAlive:             &v1alpha1.KeepAlive{ProbeInterval: ptr.To("30s"), IdleTimeout: ptr.To("2m")},
+			}},
+		},
+		"InvalidTransportDialTimeout": {
+			reason: "A transport dial timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{DialTimeout: ptr.To("3")}},
+			want:   invalidDuration("transport.dialTimeout", "3"),
+		},
+		"InvalidTransportResponseHeaderTimeout": {
+			reason: "A transport response header timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{ResponseHeaderTimeout: ptr.To("1 minute")}},
+			want:   invalidDuration("transport.responseHeaderTimeout", "1 minute"),
+		},
+		"InvalidTransportReadTimeout": {
+			reason: "A transport read timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{ReadTimeout: ptr.To("1w")}},
+			want:   invalidDuration("transport.readTimeout", "1w"),
+		},
+		"InvalidTransportWriteTimeout": {
+			reason: "A transport write timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{WriteTimeout: ptr.To("d")}},
+			want:   invalidDuration("transport.writeTimeout", "d"),
+		},
+		"InvalidKeepAliveProbeInterval": {
+			reason: "A keep-alive probe interval that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{KeepAlive: &v1alpha1.KeepAlive{ProbeInterval: ptr.To("often")}}},
+			want:   invalidDuration("transport.keepAlive.probeInterval", "often"),
+		},
+		"InvalidKeepAliveIdleTimeout": {
+			reason: "A keep-alive idle timeout that Caddy can't parse should be invalid.",
+			p:      v1alpha1.ProxyRouteParameters{Transport: &v1alpha1.HTTPTransport{KeepAlive: &v1alpha1.KeepAlive{IdleTimeout: ptr.To("2")}}},
+			want:   invalidDuration("transport.keepAlive.idleTimeout", "2"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validate(&tc.p)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidate(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
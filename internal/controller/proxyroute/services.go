@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+const (
+	errListEndpointSlices = "cannot list EndpointSlices"
+
+	errServicePortAmbiguous = "service has more than one port; specify which to use"
+
+	errFmtServicePortNotFound   = "service has no port named %q"
+	errFmtDiscoverUpstreamsFrom = "cannot discover upstreams from service %q"
+
+	// serviceIndexKey indexes ProxyRoutes by the Services they discover
+	// upstreams from, in namespace/name form.
+	serviceIndexKey = "spec.forProvider.upstreamsFrom.service"
+)
+
+// serviceOf returns the namespaced name of the Service the supplied source
+// references.
+func serviceOf(mg resource.Managed, s v1alpha1.ServiceUpstreams) (types.NamespacedName, error) {
+	ns, err := namespaceOf(mg, s.Namespace, "service")
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Namespace: ns, Name: s.Name}, nil
+}
+
+// serviceKeys returns the index keys of the Services the supplied ProxyRoute
+// discovers upstreams from.
+func serviceKeys(obj client.Object) []string {
+	mg, ok := obj.(resource.Managed)
+	if !ok {
+		return nil
+	}
+	p, _, err := proxyRouteOf(mg)
+	if err != nil {
+		return nil
+	}
+	sources := p.UpstreamsFrom
+	if p.Canary != nil {
+		sources = append(append([]v1alpha1.UpstreamSource{}, sources...), p.Canary.UpstreamsFrom...)
+	}
+	var keys []string
+	for _, src := range sources {
+		if svc, err := serviceOf(mg, src.Service); err == nil {
+			keys = append(keys, svc.String())
+		}
+	}
+	return keys
+}
+
+// enqueueForEndpointSlice returns an event handler that enqueues the
+// ProxyRoutes that discover upstreams from the Service an EndpointSlice
+// belongs to, so that they're reconciled as soon as its endpoints change.
+func enqueueForEndpointSlice(kube client.Reader, newList func() resource.ManagedList, log logging.Logger) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		name, ok := obj.GetLabels()[discoveryv1.LabelServiceName]
+		if !ok {
+			return nil
+		}
+		l := newList()
+		svc := types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}
+		if err := kube.List(ctx, l, client.MatchingFields{serviceIndexKey: svc.String()}); err != nil {
+			log.Info("Failed to list ProxyRoutes", "service", svc.String(), "error", err)
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, mg := range l.GetItems() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mg.GetNamespace(), Name: mg.GetName()}})
+		}
+		return reqs
+	})
+}
+
+// discoverUpstreams returns an upstream for each ready endpoint of the
+// supplied Service, sorted by dial address. A Service without EndpointSlices
+// has no upstreams.
+func (e *external) discoverUpstreams(ctx context.Context, mg resource.Managed, s v1alpha1.ServiceUpstreams) ([]caddyclient.Upstream, error) {
+	svc, err := serviceOf(mg, s)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &discoveryv1.EndpointSliceList{}
+	if err := e.kube.List(ctx, l, client.InNamespace(svc.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: svc.Name}); err != nil {
+		return nil, errors.Wrap(err, errListEndpointSlices)
+	}
+
+	seen := map[string]bool{}
+	var dials []string
+	for i := range l.Items {
+		es := &l.Items[i]
+		port, ok, err := endpointPort(es, s.Port)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, ep := range es.Endpoints {
+			// A nil ready condition means the endpoint's readiness is
+			// unknown, which consumers should interpret as ready.
+			if !ptr.Deref(ep.Conditions.Ready, true) {
+				continue
+			}
+			for _, addr := range ep.Addresses {
+				dial := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+				if !seen[dial] {
+					seen[dial] = true
+					dials = append(dials, dial)
+				}
+			}
+		}
+	}
+	sort.Strings(dials)
+
+	upstreams := make([]caddyclient.Upstream, len(dials))
+	for i, d := range dials {
+		upstreams[i] = caddyclient.Upstream{Dial: d}
+	}
+	return upstreams, nil
+}
+
+// endpointPort returns the port of the supplied EndpointSlice's endpoints
+// with the supplied name, or its only port if no name is supplied. It
+// returns false if the slice has no such port.
+func endpointPort(es *discoveryv1.EndpointSlice, name *string) (int32, bool, error) {
+	if name == nil {
+		switch len(es.Ports) {
+		case 0:
+			return 0, false, nil
+		case 1:
+			return ptr.Deref(es.Ports[0].Port, 0), es.Ports[0].Port != nil, nil
+		default:
+			return 0, false, errors.New(errServicePortAmbiguous)
+		}
+	}
+	for _, p := range es.Ports {
+		if ptr.Deref(p.Name, "") == *name && p.Port != nil {
+			return *p.Port, true, nil
+		}
+	}
+	if len(es.Ports) == 0 {
+		return 0, false, nil
+	}
+	return 0, false, errors.Errorf(errFmtServicePortNotFound, *name)
+}
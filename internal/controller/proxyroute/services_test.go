@@ -0,0 +1,417 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/test"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	namespacedv1alpha1 "github.com/crossplane/provider-caddy/apis/namespaced/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+// endpointSlice returns an EndpointSlice of Service web in namespace team-a
+// with the supplied ports and endpoints.
+func endpointSlice(ports []discoveryv1.EndpointPort, eps ...discoveryv1.Endpoint) discoveryv1.EndpointSlice {
+	return discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}},
+		Ports:      ports,
+		Endpoints:  eps,
+	}
+}
+
+// endpoint returns an endpoint with the supplied addresses and conditions.
+func endpoint(c discoveryv1.EndpointConditions, addrs ...string) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{Addresses: addrs, Conditions: c}
+}
+
+// port returns an EndpointSlice port with the supplied name and number. An
+// empty name is an unnamed port, and a zero number is an unset one.
+func port(name string, number int32) discoveryv1.EndpointPort {
+	p := discoveryv1.EndpointPort{Name: ptr.To(name)}
+	if number != 0 {
+		p.Port = ptr.To(number)
+	}
+	return p
+}
+
+func TestEndpointPort(t *testing.T) {
+	type args struct {
+		ports []discoveryv1.EndpointPort
+		name  *string
+	}
+	type want struct {
+		port int32
+		ok   bool
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoPorts": {
+			reason: "A slice without ports has no port to proxy to.",
+			args:   args{},
+			want:   want{},
+		},
+		"OnlyPort": {
+			reason: "A slice's only port should be used if no name is supplied.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080)}},
+			want:   want{port: 8080, ok: true},
+		},
+		"OnlyUnnamedPort": {
+			reason: "The unnamed port of a single port Service should be used if no name is supplied.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("", 8080)}},
+			want:   want{port: 8080, ok: true},
+		},
+		"OnlyPortUnset": {
+			reason: "A slice whose only port has no number has no port to proxy to.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("", 0)}},
+			want:   want{},
+		},
+		"Ambiguous": {
+			reason: "A name must be supplied if a slice has more than one port.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080), port("metrics", 9090)}},
+			want:   want{err: errors.New(errServicePortAmbiguous)},
+		},
+		"Named": {
+			reason: "The port with the supplied name should be used.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080), port("metrics", 9090)}, name: ptr.To("metrics")},
+			want:   want{port: 9090, ok: true},
+		},
+		"NamedOnlyPort": {
+			reason: "A slice's only port should only be used if it has the supplied name.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080)}, name: ptr.To("http")},
+			want:   want{port: 8080, ok: true},
+		},
+		"NamedNotFound": {
+			reason: "It should be an error for a slice to have no port with the supplied name.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080)}, name: ptr.To("grpc")},
+			want:   want{err: errors.Errorf(errFmtServicePortNotFound, "grpc")},
+		},
+		"NamedUnset": {
+			reason: "A port with the supplied name but no number should not be used.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 0)}, name: ptr.To("http")},
+			want:   want{err: errors.Errorf(errFmtServicePortNotFound, "http")},
+		},
+		"NamedNoPorts": {
+			reason: "A slice without ports has no port to proxy to, whatever name is supplied.",
+			args:   args{name: ptr.To("http")},
+			want:   want{},
+		},
+		"NumberIsNotName": {
+			reason: "Ports are selected by name, so a port number should not match a port.",
+			args:   args{ports: []discoveryv1.EndpointPort{port("http", 8080), port("metrics", 9090)}, name: ptr.To("8080")},
+			want:   want{err: errors.Errorf(errFmtServicePortNotFound, "8080")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			es := &discoveryv1.EndpointSlice{Ports: tc.args.ports}
+			p, ok, err := endpointPort(es, tc.args.name)
+			got := want{port: p, ok: ok, err: err}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nendpointPort(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDiscoverUpstreams(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	ready := discoveryv1.EndpointConditions{Ready: ptr.To(true)}
+	notReady := discoveryv1.EndpointConditions{Ready: ptr.To(false)}
+	terminating := discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)}
+	http := []discoveryv1.EndpointPort{port("http", 8080)}
+
+	// list returns a List function that returns the supplied EndpointSlices
+	// when they're listed by the labels of Service web in namespace team-a.
+	list := func(slices ...discoveryv1.EndpointSlice) test.MockListFn {
+		return func(_ context.Context, l client.ObjectList, opts ...client.ListOption) error {
+			o := &client.ListOptions{}
+			o.ApplyOptions(opts)
+			if o.Namespace != "team-a" || o.LabelSelector == nil || !o.LabelSelector.Matches(labels.Set{discoveryv1.LabelServiceName: "web"}) {
+				return errors.New("EndpointSlices must be listed by Service")
+			}
+			l.(*discoveryv1.EndpointSliceList).Items = slices
+			return nil
+		}
+	}
+
+	type args struct {
+		list test.MockListFn
+		mg   resource.Managed
+		s    v1alpha1.ServiceUpstreams
+	}
+	type want struct {
+		upstreams []caddyclient.Upstream
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoEndpointSlices": {
+			reason: "A Service without EndpointSlices should have no upstreams.",
+			args: args{
+				list: list(),
+				mg:   namespacedProxyRoute("a"),
+				s:    v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{}},
+		},
+		"ReadyEndpoints": {
+			reason: "Each address of a ready endpoint, or one whose readiness is unknown, should be an upstream.",
+			args: args{
+				list: list(endpointSlice(http,
+					endpoint(ready, "10.0.0.2", "10.0.0.3"),
+					endpoint(discoveryv1.EndpointConditions{}, "10.0.0.1"),
+				)),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}, {Dial: "10.0.0.2:8080"}, {Dial: "10.0.0.3:8080"}}},
+		},
+		"NotReadyEndpoints": {
+			reason: "Endpoints that aren't ready should not be upstreams.",
+			args: args{
+				list: list(endpointSlice(http,
+					endpoint(ready, "10.0.0.1"),
+					endpoint(notReady, "10.0.0.2"),
+				)),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}}},
+		},
+		"TerminatingEndpoints": {
+			reason: "Terminating endpoints should not be upstreams, even while they're still serving.",
+			args: args{
+				list: list(endpointSlice(http,
+					endpoint(ready, "10.0.0.1"),
+					endpoint(terminating, "10.0.0.2"),
+				)),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}}},
+		},
+		"MultipleEndpointSlices": {
+			reason: "The endpoints of all of a Service's EndpointSlices should be upstreams, sorted and without duplicates.",
+			args: args{
+				list: list(
+					endpointSlice(http, endpoint(ready, "10.0.0.2"), endpoint(ready, "10.0.0.1")),
+					endpointSlice(http, endpoint(ready, "fd00::1")),
+					endpointSlice(http, endpoint(ready, "10.0.0.1")),
+				),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}, {Dial: "10.0.0.2:8080"}, {Dial: "[fd00::1]:8080"}}},
+		},
+		"NamedPortPerSlice": {
+			reason: "The named port should be read from each EndpointSlice, since a Service's target port may differ between its endpoints.",
+			args: args{
+				list: list(
+					endpointSlice([]discoveryv1.EndpointPort{port("http", 8080), port("metrics", 9090)}, endpoint(ready, "10.0.0.1")),
+					endpointSlice([]discoveryv1.EndpointPort{port("metrics", 9091), port("http", 8081)}, endpoint(ready, "10.0.0.2")),
+				),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web", Port: ptr.To("http")},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}, {Dial: "10.0.0.2:8081"}}},
+		},
+		"EndpointSliceWithoutPorts": {
+			reason: "The endpoints of an EndpointSlice without ports should not be upstreams.",
+			args: args{
+				list: list(
+					endpointSlice(nil, endpoint(ready, "10.0.0.1")),
+					endpointSlice(http, endpoint(ready, "10.0.0.2")),
+				),
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.2:8080"}}},
+		},
+		"AmbiguousPort": {
+			reason: "It should be an error not to name the port of a Service with more than one.",
+			args: args{
+				list: list(endpointSlice([]discoveryv1.EndpointPort{port("http", 8080), port("metrics", 9090)}, endpoint(ready, "10.0.0.1"))),
+				mg:   namespacedProxyRoute("a"),
+				s:    v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{err: errors.New(errServicePortAmbiguous)},
+		},
+		"ClusterScoped": {
+			reason: "A cluster scoped ProxyRoute should discover upstreams from a Service in the namespace it specifies.",
+			args: args{
+				list: list(endpointSlice(http, endpoint(ready, "10.0.0.1"))),
+				mg:   proxyRoute(),
+				s:    v1alpha1.ServiceUpstreams{Name: "web", Namespace: ptr.To("team-a")},
+			},
+			want: want{upstreams: []caddyclient.Upstream{{Dial: "10.0.0.1:8080"}}},
+		},
+		"ClusterScopedNoNamespace": {
+			reason: "It should be an error for a cluster scoped ProxyRoute not to specify the Service's namespace.",
+			args: args{
+				mg: proxyRoute(),
+				s:  v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{err: errors.Errorf(errFmtRefNoNamespace, "service")},
+		},
+		"ForeignNamespace": {
+			reason: "It should be an error for a namespaced ProxyRoute to discover upstreams from a Service in another namespace.",
+			args: args{
+				mg: namespacedProxyRoute("a"),
+				s:  v1alpha1.ServiceUpstreams{Name: "web", Namespace: ptr.To("team-b")},
+			},
+			want: want{err: errors.Errorf(errFmtRefNamespace, "service", "team-a")},
+		},
+		"ListError": {
+			reason: "Errors listing EndpointSlices should be returned.",
+			args: args{
+				list: test.NewMockListFn(errBoom),
+				mg:   namespacedProxyRoute("a"),
+				s:    v1alpha1.ServiceUpstreams{Name: "web"},
+			},
+			want: want{err: errors.Wrap(errBoom, errListEndpointSlices)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{kube: &test.MockClient{MockList: tc.args.list}}
+			upstreams, err := e.discoverUpstreams(context.Background(), tc.args.mg, tc.args.s)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.discoverUpstreams(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.upstreams, upstreams); diff != "" {
+				t.Errorf("\n%s\ne.discoverUpstreams(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEnqueueForEndpointSlice(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// list returns a List function that returns two ProxyRoutes when they're
+	// listed by Service web in namespace team-a.
+	list := func(_ context.Context, l client.ObjectList, opts ...client.ListOption) error {
+		o := &client.ListOptions{}
+		o.ApplyOptions(opts)
+		if o.FieldSelector == nil {
+			return errors.New("ProxyRoutes must be listed by Service")
+		}
+		if o.FieldSelector.Matches(fields.Set{serviceIndexKey: "team-a/web"}) {
+			l.(*namespacedv1alpha1.ProxyRouteList).Items = []namespacedv1alpha1.ProxyRoute{
+				{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "a"}},
+				{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "b"}},
+			}
+		}
+		return nil
+	}
+
+	type args struct {
+		list test.MockListFn
+		obj  client.Object
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []reconcile.Request
+	}{
+		"ServiceEndpointSlice": {
+			reason: "The ProxyRoutes that discover upstreams from an EndpointSlice's Service should be enqueued.",
+			args: args{
+				list: list,
+				obj:  &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}}},
+			},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "a"}},
+				{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "b"}},
+			},
+		},
+		"UnusedService": {
+			reason: "Nothing should be enqueued for an EndpointSlice of a Service no ProxyRoute discovers upstreams from.",
+			args: args{
+				list: list,
+				obj:  &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "web-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}}},
+			},
+		},
+		"NoService": {
+			reason: "Nothing should be enqueued for an EndpointSlice that doesn't belong to a Service.",
+			args: args{
+				list: test.NewMockListFn(errors.New("ProxyRoutes should not be listed")),
+				obj:  &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "custom"}},
+			},
+		},
+		"ListError": {
+			reason: "Nothing should be enqueued if ProxyRoutes can't be listed.",
+			args: args{
+				list: test.NewMockListFn(errBoom),
+				obj:  &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "web"}}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			newList := func() resource.ManagedList { return &namespacedv1alpha1.ProxyRouteList{} }
+			h := enqueueForEndpointSlice(&test.MockClient{MockList: tc.args.list}, newList, logging.NewNopLogger())
+
+			q := &controllertest.Queue{TypedInterface: workqueue.NewTyped[reconcile.Request]()}
+			h.Create(context.Background(), event.CreateEvent{Object: tc.args.obj}, q)
+
+			var got []reconcile.Request
+			for q.Len() > 0 {
+				r, _ := q.Get()
+				got = append(got, r)
+				q.Done(r)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nenqueueForEndpointSlice(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/resource"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+// networkPrefixes are the network types Caddy accepts in front of a dial
+// address, e.g. "tcp/localhost:8080".
+var networkPrefixes = []string{"tcp/", "tcp4/", "tcp6/"}
+
+// A weightedUpstream is an upstream and its weight relative to the other
+// upstreams in its group.
+type weightedUpstream struct {
+	caddyclient.Upstream
+	weight int
+}
+
+// upstreamGroups are the upstreams a route proxies to. The canary group
+// receives a percentage of traffic, and the stable group the remainder.
+type upstreamGroups struct {
+	stable []weightedUpstream
+	canary []weightedUpstream
+}
+
+// upstreamGroups returns the declared and discovered upstreams of the
+// supplied ProxyRoute.
+func (e *external) upstreamGroups(ctx context.Context, mg resource.Managed, p *v1alpha1.ProxyRouteParameters) (upstreamGroups, error) {
+	g := upstreamGroups{}
+	var err error
+	if g.stable, err = e.resolveUpstreams(ctx, mg, p.Upstreams, p.UpstreamsFrom); err != nil {
+		return upstreamGroups{}, err
+	}
+	if c := p.Canary; c != nil {
+		if g.canary, err = e.resolveUpstreams(ctx, mg, c.Upstreams, c.UpstreamsFrom); err != nil {
+			return upstreamGroups{}, err
+		}
+	}
+	return g, nil
+}
+
+// resolveUpstreams returns the supplied static upstreams, followed by those
+// discovered from the supplied sources. Discovered upstreams have weight 1.
+func (e *external) resolveUpstreams(ctx context.Context, mg resource.Managed, static []v1alpha1.Upstream, from []v1alpha1.UpstreamSource) ([]weightedUpstream, error) {
+	out := make([]weightedUpstream, 0, len(static))
+	for _, u := range static {
+		out = append(out, weightedUpstream{
+			Upstream: caddyclient.Upstream{Dial: u.Dial, MaxRequests: ptr.Deref(u.MaxRequests, 0)},
+			weight:   ptr.Deref(u.Weight, 1),
+		})
+	}
+	for _, src := range from {
+		discovered, err := e.discoverUpstreams(ctx, mg, src.Service)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtDiscoverUpstreamsFrom, src.Service.Name)
+		}
+		for _, u := range discovered {
+			out = append(out, weightedUpstream{Upstream: u, weight: 1})
+		}
+	}
+	return out, nil
+}
+
+// splitTraffic returns the upstreams of the supplied groups and their
+// weights, such that the canary group receives the supplied percentage of
+// traffic and each upstream a share of its group's traffic proportional to
+// its weight. Upstreams that would receive no traffic are omitted rather
+// than given zero weight. If either group has no upstreams the other
+// receives all traffic.
+func splitTraffic(g upstreamGroups, canaryPercent int) ([]caddyclient.Upstream, []int) {
+	sum := func(us []weightedUpstream) int {
+		total := 0
+		for _, u := range us {
+			total += u.weight
+		}
+		return total
+	}
+	stableTotal, canaryTotal := sum(g.stable), sum(g.canary)
+	switch {
+	case canaryTotal == 0:
+		canaryPercent = 0
+	case stableTotal == 0:
+		canaryPercent = 100
+	}
+
+	// Scale each group's weights by its percentage and by the other group's
+	// total weight, so that the groups' totals are in the ratio of their
+	// percentages without rounding.
+	var upstreams []caddyclient.Upstream
+	var weights []int
+	add := func(us []weightedUpstream, scale int) {
+		for _, u := range us {
+			if w := u.weight * scale; w > 0 {
+				upstreams = append(upstreams, u.Upstream)
+				weights = append(weights, w)
+			}
+		}
+	}
+	if canaryTotal == 0 {
+		add(g.stable, 1)
+	} else {
+		add(g.stable, (100-canaryPercent)*canaryTotal)
+		add(g.canary, canaryPercent*max(stableTotal, 1))
+	}
+
+	d := 0
+	for _, w := range weights {
+		d = gcd(d, w)
+	}
+	for i := range weights {
+		weights[i] /= d
+	}
+	return upstreams, weights
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// defaultPortOf returns the port Caddy dials upstreams of the ProxyRoute with
+// the supplied parameters on when their address doesn't specify one.
+func defaultPortOf(p *v1alpha1.ProxyRouteParameters) string {
+	if t := p.TLS; t != nil && t.Enabled != nil && *t.Enabled {
+		return "443"
+	}
+	return "80"
+}
+
+// maxFails returns how many failed requests make Caddy consider an upstream
+// of the ProxyRoute with the supplied parameters unhealthy. Caddy only counts
+// failed requests if passive health checks remember them for a fail duration,
+// so maxFails returns false if they don't.
+func maxFails(p *v1alpha1.ProxyRouteParameters) (int, bool) {
+	hc := p.HealthChecks
+	if hc == nil || hc.Passive == nil || hc.Passive.FailDuration == nil {
+		return 0, false
+	}
+	if d, err := caddyclient.ParseDuration(*hc.Passive.FailDuration); err != nil || d <= 0 {
+		return 0, false
+	}
+	if m := hc.Passive.MaxFails; m != nil && *m > 0 {
+		return *m, true
+	}
+	// Caddy's default.
+	return 1, true
+}
+
+// upstreamHealthy reports whether Caddy considers the upstream with the
+// supplied status healthy, and whether that is known. Caddy proxies requests
+// to upstreams whose health it doesn't know.
+func upstreamHealthy(p *v1alpha1.ProxyRouteParameters, s caddyclient.UpstreamStatus) (healthy, known bool) {
+	limit, known := maxFails(p)
+	if !known {
+		return true, false
+	}
+	return s.Fails < limit, true
+}
+
+// reportedUpstreams returns the supplied upstream statuses Caddy reports,
+// keyed by their normalized dial address.
+func reportedUpstreams(p *v1alpha1.ProxyRouteParameters, all []caddyclient.UpstreamStatus) map[string]caddyclient.UpstreamStatus {
+	reported := make(map[string]caddyclient.UpstreamStatus, len(all))
+	for _, u := range all {
+		reported[normalizeDial(u.Address, defaultPortOf(p))] = u
+	}
+	return reported
+}
+
+// observeUpstreams updates the upstream status of the ProxyRoute with the
+// supplied parameters from the status Caddy reports for all upstreams. Caddy
+// pools upstreams across routes, so only the supplied upstreams the route
+// declares or discovers are recorded.
+func observeUpstreams(p *v1alpha1.ProxyRouteParameters, upstreams []caddyclient.Upstream, o *v1alpha1.ProxyRouteObservation, all []caddyclient.UpstreamStatus) {
+	reported := reportedUpstreams(p, all)
+
+	var statuses []v1alpha1.UpstreamStatus
+	declared := map[string]bool{}
+	healthy := 0
+	for _, u := range upstreams {
+		addr := normalizeDial(u.Dial, defaultPortOf(p))
+		if declared[addr] {
+			continue
+		}
+		declared[addr] = true
+
+		s, ok := reported[addr]
+		if !ok {
+			// Caddy has not (yet) registered this upstream, so its health
+			// is unknown.
+			continue
+		}
+		status := v1alpha1.UpstreamStatus{
+			Address:     s.Address,
+			Fails:       s.Fails,
+			NumRequests: s.NumRequests,
+		}
+		ok, known := upstreamHealthy(p, s)
+		if ok {
+			healthy++
+		}
+		if known {
+			status.Healthy = ptr.To(ok)
+		}
+		statuses = append(statuses, status)
+	}
+
+	o.UpstreamStatuses = statuses
+	o.HealthyUpstreams = healthy
+	o.TotalUpstreams = len(declared)
+	o.UpstreamSummary = fmt.Sprintf("%d/%d", healthy, len(declared))
+}
+
+// observeDynamicUpstreams clears the upstream status of a ProxyRoute whose
+// upstreams Caddy looks up dynamically, and whose status it doesn't report.
+func observeDynamicUpstreams(o *v1alpha1.ProxyRouteObservation) {
+	o.UpstreamStatuses = nil
+	o.HealthyUpstreams = 0
+	o.TotalUpstreams = 0
+	o.UpstreamSummary = "dynamic"
+}
+
+// normalizeDial returns the canonical form of a dial address, so that
+// addresses Caddy treats as equivalent compare equal. Addresses without a
+// port are assumed to use the supplied default port.
+func normalizeDial(addr, defaultPort string) string {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	for _, p := range networkPrefixes {
+		if strings.HasPrefix(addr, p) {
+			addr = strings.TrimPrefix(addr, p)
+			break
+		}
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		// The address has no port. Strip the brackets of a bare IPv6
+		// address; JoinHostPort adds them back.
+		host, port = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"), defaultPort
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2025 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyroute
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"github.com/crossplane/provider-caddy/apis/config/v1alpha1"
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
+)
+
+func TestSplitTraffic(t *testing.T) {
+	upstream := func(dial string, weight int) weightedUpstream {
+		return weightedUpstream{Upstream: caddyclient.Upstream{Dial: dial}, weight: weight}
+	}
+	dials := func(d ...string) []caddyclient.Upstream {
+		out := make([]caddyclient.Upstream, len(d))
+		for i := range d {
+			out[i] = caddyclient.Upstream{Dial: d[i]}
+		}
+		return out
+	}
+
+	type args struct {
+		g             upstreamGroups
+		canaryPercent int
+	}
+	type want struct {
+		upstreams []caddyclient.Upstream
+		weights   []int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoCanary": {
+			reason: "Without a canary group the stable group's weights should be used as is.",
+			args:   args{g: upstreamGroups{stable: []weightedUpstream{upstream("a", 1), upstream("b", 3)}}},
+			want:   want{upstreams: dials("a", "b"), weights: []int{1, 3}},
+		},
+		"NoCanaryReduced": {
+			reason: "Weights should be reduced by their greatest common divisor.",
+			args:   args{g: upstreamGroups{stable: []weightedUpstream{upstream("a", 2), upstream("b", 4)}}},
+			want:   want{upstreams: dials("a", "b"), weights: []int{1, 2}},
+		},
+		"EmptyCanary": {
+			reason: "A canary group without upstreams should receive no traffic, whatever its percentage.",
+			args:   args{g: upstreamGroups{stable: []weightedUpstream{upstream("a", 1)}}, canaryPercent: 50},
+			want:   want{upstreams: dials("a"), weights: []int{1}},
+		},
+		"Split": {
+			reason: "The canary group should receive its percentage of traffic.",
+			args: args{
+				g:             upstreamGroups{stable: []weightedUpstream{upstream("a", 1)}, canary: []weightedUpstream{upstream("c", 1)}},
+				canaryPercent: 10,
+			},
+			want: want{upstreams: dials("a", "c"), weights: []int{9, 1}},
+		},
+		"SplitWeighted": {
+			reason: "Each group's traffic should be shared by its upstreams in proportion to their weights.",
+			args: args{
+				g: upstreamGroups{
+					stable: []weightedUpstream{upstream("a", 1), upstream("b", 3)},
+					canary: []weightedUpstream{upstream("c", 1), upstream("d", 1)},
+				},
+				canaryPercent: 20,
+			},
+			// Stable: 80% split 1:3, i.e. 20% and 60%. Canary: 20% split
+			// 1:1, i.e. 10% and 10%.
+			want: want{upstreams: dials("a", "b", "c", "d"), weights: []int{2, 6, 1, 1}},
+		},
+		"ZeroPercent": {
+			reason: "A canary group with no traffic should be omitted rather than given zero weight.",
+			args: args{
+				g:             upstreamGroups{stable: []weightedUpstream{upstream("a", 1)}, canary: []weightedUpstream{upstream("c", 1)}},
+				canaryPercent: 0,
+			},
+			want: want{upstreams: dials("a"), weights: []int{1}},
+		},
+		"HundredPercent": {
+			reason: "A stable group with no traffic should be omitted rather than given zero weight.",
+			args: args{
+				g:             upstreamGroups{stable: []weightedUpstream{upstream("a", 1)}, canary: []weightedUpstream{upstream("c", 1)}},
+				canaryPercent: 100,
+			},
+			want: want{upstreams: dials("c"), weights: []int{1}},
+		},
+		"EmptyStable": {
+			reason: "A stable group without upstreams should leave all traffic to the canary group.",
+			args: args{
+				g:             upstreamGroups{canary: []weightedUpstream{upstream("c", 1), upstream("d", 2)}},
+				canaryPercent: 10,
+			},
+			want: want{upstreams: dials("c", "d"), weights: []int{1, 2}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			upstreams, weights := splitTraffic(tc.args.g, tc.args.canaryPercent)
+			if diff := cmp.Diff(tc.want.upstreams, upstreams); diff != "" {
+				t.Errorf("\n%s\nsplitTraffic(...): -want upstreams, +got upstreams:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.weights, weights); diff != "" {
+				t.Errorf("\n%s\nsplitTraffic(...): -want weights, +got weights:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeDial(t *testing.T) {
+	type args struct {
+		addr        string
+		defaultPort string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"HostPort": {
+			reason: "An address with a port should be unchanged.",
+			args:   args{addr: "backend:8080", defaultPort: "80"},
+			want:   "backend:8080",
+		},
+		"DefaultPort": {
+			reason: "An address without a port should use the default port.",
+			args:   args{addr: "backend", defaultPort: "80"},
+			want:   "backend:80",
+		},
+		"DefaultTLSPort": {
+			reason: "An address without a port should use the supplied default port, e.g. 443 for TLS.",
+			args:   args{addr: "backend", defaultPort: "443"},
+			want:   "backend:443",
+		},
+		"Case": {
+			reason: "Host names should compare case-insensitively.",
+			args:   args{addr: "Backend.Example.COM:8080", defaultPort: "80"},
+			want:   "backend.example.com:8080",
+		},
+		"Whitespace": {
+			reason: "Surrounding whitespace should be ignored.",
+			args:   args{addr: " backend:8080 ", defaultPort: "80"},
+			want:   "backend:8080",
+		},
+		"NetworkPrefix": {
+			reason: "A tcp/ network prefix should be ignored.",
+			args:   args{addr: "tcp/backend:8080", defaultPort: "80"},
+			want:   "backend:8080",
+		},
+		"IPv4NetworkPrefix": {
+			reason: "A tcp4/ network prefix should be ignored.",
+			args:   args{addr: "tcp4/10.0.0.1", defaultPort: "80"},
+			want:   "10.0.0.1:80",
+		},
+		"IPv6": {
+			reason: "An IPv6 address with a port should keep its brackets.",
+			args:   args{addr: "[2001:DB8::1]:8080", defaultPort: "80"},
+			want:   "[2001:db8::1]:8080",
+		},
+		"IPv6DefaultPort": {
+			reason: "A bracketed IPv6 address without a port should use the default port.",
+			args:   args{addr: "[::1]", defaultPort: "80"},
+			want:   "[::1]:80",
+		},
+		"IPv6NetworkPrefix": {
+			reason: "A tcp6/ network prefix should be ignored.",
+			args:   args{addr: "tcp6/[::1]:8080", defaultPort: "80"},
+			want:   "[::1]:8080",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, normalizeDial(tc.args.addr, tc.args.defaultPort)); diff != "" {
+				t.Errorf("\n%s\nnormalizeDial(%q, %q): -want, +got:\n%s\n", tc.reason, tc.args.addr, tc.args.defaultPort, diff)
+			}
+		})
+	}
+}
+
+func TestObserveUpstreams(t *testing.T) {
+	passive := &v1alpha1.HealthChecks{Passive: &v1alpha1.PassiveHealthCheck{FailDuration: ptr.To("30s")}}
+
+	type args struct {
+		p         *v1alpha1.ProxyRouteParameters
+		upstreams []caddyclient.Upstream
+		all       []caddyclient.UpstreamStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   *v1alpha1.ProxyRouteObservation
+	}{
+		"NotReported": {
+			reason: "Upstreams Caddy hasn't registered yet should count towards the total, but have no status.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive},
+				upstreams: []caddyclient.Upstream{{Dial: "a:80"}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{TotalUpstreams: 1, UpstreamSummary: "0/1"},
+		},
+		"OtherRoutes": {
+			reason: "Upstreams of other routes, which Caddy pools with this route's, should be ignored.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive},
+				upstreams: []caddyclient.Upstream{{Dial: "a:80"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "a:80"}, {Address: "b:80", Fails: 1}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(true)}},
+				HealthyUpstreams: 1,
+				TotalUpstreams:   1,
+				UpstreamSummary:  "1/1",
+			},
+		},
+		"DefaultPort": {
+			reason: "A desired dial without a port should match the address Caddy reports with the default port.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive},
+				upstreams: []caddyclient.Upstream{{Dial: "a"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "a:80", Fails: 1}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(false), Fails: 1}},
+				TotalUpstreams:   1,
+				UpstreamSummary:  "0/1",
+			},
+		},
+		"DefaultTLSPort": {
+			reason: "A desired dial without a port should match the address Caddy reports with port 443 when TLS is enabled.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive, TLS: &v1alpha1.UpstreamTLS{Enabled: ptr.To(true)}},
+				upstreams: []caddyclient.Upstream{{Dial: "a"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "a:80"}, {Address: "a:443", NumRequests: 2}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "a:443", Healthy: ptr.To(true), NumRequests: 2}},
+				HealthyUpstreams: 1,
+				TotalUpstreams:   1,
+				UpstreamSummary:  "1/1",
+			},
+		},
+		"IPv6": {
+			reason: "A desired IPv6 dial should match the address Caddy reports, regardless of network prefix and case.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive},
+				upstreams: []caddyclient.Upstream{{Dial: "tcp6/[2001:DB8::1]:8080"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "[2001:db8::1]:8080"}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "[2001:db8::1]:8080", Healthy: ptr.To(true)}},
+				HealthyUpstreams: 1,
+				TotalUpstreams:   1,
+				UpstreamSummary:  "1/1",
+			},
+		},
+		"Duplicates": {
+			reason: "Dials that normalize to the same address should count once.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{HealthChecks: passive},
+				upstreams: []caddyclient.Upstream{{Dial: "a:80"}, {Dial: "tcp/A"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "a:80"}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "a:80", Healthy: ptr.To(true)}},
+				HealthyUpstreams: 1,
+				TotalUpstreams:   1,
+				UpstreamSummary:  "1/1",
+			},
+		},
+		"UnknownHealth": {
+			reason: "Without passive health checks health should be unknown, and every reported upstream should count as healthy.",
+			args: args{
+				p:         &v1alpha1.ProxyRouteParameters{},
+				upstreams: []caddyclient.Upstream{{Dial: "a:80"}, {Dial: "b:80"}},
+				all:       []caddyclient.UpstreamStatus{{Address: "a:80"}, {Address: "b:80"}},
+			},
+			want: &v1alpha1.ProxyRouteObservation{
+				UpstreamStatuses: []v1alpha1.UpstreamStatus{{Address: "a:80"}, {Address: "b:80"}},
+				HealthyUpstreams: 2,
+				TotalUpstreams:   2,
+				UpstreamSummary:  "2/2",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := &v1alpha1.ProxyRouteObservation{}
+			observeUpstreams(tc.args.p, tc.args.upstreams, o, tc.args.all)
+			if diff := cmp.Diff(tc.want, o); diff != "" {
+				t.Errorf("\n%s\nobserveUpstreams(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
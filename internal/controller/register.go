@@ -20,16 +20,18 @@ import (
 	"github.com/crossplane/crossplane-runtime/v2/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	caddyclient "github.com/crossplane/provider-caddy/internal/clients/caddy"
 	"github.com/crossplane/provider-caddy/internal/controller/config"
 	"github.com/crossplane/provider-caddy/internal/controller/proxyroute"
 )
 
 // SetupGated creates all Caddy controllers with safe-start support and adds them to
-// the supplied manager.
-func SetupGated(mgr ctrl.Manager, o controller.Options) error {
+// the supplied manager. Controllers that talk to the Caddy admin API use the
+// supplied request options unless a ProviderConfig overrides them.
+func SetupGated(mgr ctrl.Manager, o controller.Options, ro caddyclient.RequestOptions) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
-		proxyroute.SetupGated,
+		func(mgr ctrl.Manager, o controller.Options) error { return proxyroute.SetupGated(mgr, o, ro) },
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err